@@ -2,29 +2,30 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"flag"
 	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
-
-	//"os"
+	"syscall"
 	"time"
 
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
-
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
+	"github.com/kerilOvs/profile_sevice/internal/api"
+	"github.com/kerilOvs/profile_sevice/internal/auth"
 	"github.com/kerilOvs/profile_sevice/internal/config"
+	"github.com/kerilOvs/profile_sevice/internal/events"
 	"github.com/kerilOvs/profile_sevice/internal/handlers"
-	"github.com/kerilOvs/profile_sevice/internal/models"
+	"github.com/kerilOvs/profile_sevice/internal/outbox"
 	"github.com/kerilOvs/profile_sevice/internal/service"
 	"github.com/kerilOvs/profile_sevice/internal/storage/minio"
 	"github.com/kerilOvs/profile_sevice/internal/storage/rabbit"
 
 	//"github.com/kerilOvs/profile_sevice/logger"
-	postgresstorage "github.com/kerilOvs/profile_sevice/internal/storage/postgres"
 	"github.com/kerilOvs/profile_sevice/pkg/logger"
 )
 
@@ -34,6 +35,7 @@ func main() {
 	//logFormat := os.Getenv("LOG_FORMAT")
 	//log := logger.Init(logFormat, logLevel)
 	// 1. Загрузка конфигурации
+	flag.Parse()
 	log := logger.Init("text", "debug")
 	cfg, err := config.ReadConfig()
 	if err != nil {
@@ -41,37 +43,56 @@ func main() {
 	}
 	log.Info("Read config", slog.Any("config", cfg))
 
-	log.Info("Connecting to db...")
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		cfg.Database.Host,
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.Dbname,
-		strconv.Itoa(cfg.Database.Port),
-	)
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	stores, err := newStorage(cfg, log)
 	if err != nil {
-		log.Error("Failed to connect to database:", slog.Any("error", err))
+		log.Error("failed to create storage", slog.Any("error", err))
+
+		return
 	}
 
-	sqlDB, err := db.DB()
-	if err != nil {
-		panic("failed to get underlying sql.DB")
+	// Подкоманды работают только с базой: service [-config file] <command> [flags]
+	switch flag.Arg(0) {
+	case "":
+	case "migrate":
+		if stores.migrator == nil {
+			log.Error("migrate requires the postgres storage backend", slog.String("backend", stores.name))
+			os.Exit(2)
+		}
+		cmdCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		err := runMigrate(cmdCtx, stores.migrator, flag.Args()[1:])
+		stop()
+		if err != nil {
+			log.Error("migrate failed", slog.Any("error", err))
+			os.Exit(1)
+		}
+		return
+	case "resync":
+		// Outbox в памяти живет только в этом процессе, relay сервиса его не увидит
+		if stores.name != "postgres" {
+			log.Error("resync requires the postgres storage backend", slog.String("backend", stores.name))
+			os.Exit(2)
+		}
+		cmdCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		userService := service.NewUserService(stores.users, cfg.Events)
+		err := runResync(cmdCtx, userService, flag.Args()[1:], log)
+		stop()
+		if err != nil {
+			log.Error("resync failed", slog.Any("error", err))
+			os.Exit(1)
+		}
+		return
+	default:
+		log.Error("unknown command", slog.String("command", flag.Arg(0)))
+		os.Exit(2)
 	}
 
-	// Настройка пула соединений
-	sqlDB.SetMaxIdleConns(10)           // Максимальное количество бездействующих соединений
-	sqlDB.SetMaxOpenConns(100)          // Максимальное количество открытых соединений
-	sqlDB.SetConnMaxLifetime(time.Hour) // Максимальное время жизни соединения
-
-	log.Info("Running migrations...")
-	if err := db.AutoMigrate(
-		&models.User{},
-		&models.UserPhoto{},
-		&models.UserTag{},
-	); err != nil {
-		log.Error("Failed to migrate database:", slog.Any("error", err))
+	if cfg.Database.AutoMigrate && stores.migrator != nil {
+		log.Info("Running migrations...")
+		if err := stores.migrator.Up(context.Background()); err != nil {
+			log.Error("Failed to migrate database:", slog.Any("error", err))
+
+			return
+		}
 	}
 
 	// 4. Инициализация MinIO клиента
@@ -84,24 +105,138 @@ func main() {
 		log.Error("Failed to initialize MinIO client:", slog.Any("error", err))
 	}
 
-	log.Info("Connecting to Rabbit")
-	rabbitRepo, err := rabbit.New(ctx, &cfg.Rabbit)
+	publisher, publisherReady, err := newPublisher(ctx, cfg, log)
+	if err != nil {
+		log.Error("failed to create events publisher", slog.Any("error", err))
+
+		return
+	}
+	defer closePublisher(publisher, log)
+
+	verifier, err := auth.NewVerifier(cfg.Auth)
+	if err != nil {
+		log.Error("failed to create jwt verifier", slog.Any("error", err))
+
+		return
+	}
+
+	apiKeys, err := auth.NewAPIKeys(cfg.Auth.APIKeys)
+	if err != nil {
+		log.Error("failed to load api keys", slog.Any("error", err))
+
+		return
+	}
+
+	validator, err := handlers.NewOpenAPIValidator(cfg.Server.ValidateResponses, log)
 	if err != nil {
-		log.Error("failed to create rabbit_repo", slog.Any("error", err))
+		log.Error("failed to create openapi validator", slog.Any("error", err))
 
 		return
 	}
 
 	// 5. Инициализация слоев приложения
-	userStorage := postgresstorage.NewUserPostgresStorage(db)
-	userService := service.NewUserService(userStorage, rabbitRepo)
+	userService := service.NewUserService(stores.users, cfg.Events)
+
+	// Фоновые задачи останавливаются при завершении. Publisher закрывается только после
+	// того, как relay дошлет outbox и вернется (defer выполняются в обратном порядке)
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	relayDone := make(chan struct{})
+	defer func() {
+		stopBackground()
+		<-relayDone
+	}()
+
+	// События из outbox публикуются в фоне, даже если при записи брокер был недоступен
+	relay := outbox.NewRelay(stores.outbox, publisher, cfg.Outbox, log)
+	go func() {
+		defer close(relayDone)
+		relay.Run(backgroundCtx)
+	}()
 
 	// Инициализация фото сервиса
 	photoService := service.NewPhotoService(minioClient.Client, cfg.Minio)
 	photoHandler := handlers.NewPhotoHandler(userService, photoService)
 
 	// 6. Настройка Echo сервера
+	e := newEcho(log, verifier, apiKeys, validator)
+
+	// 7. Регистрация маршрутов
+	userHandler := handlers.NewUserHandler(userService)
+	adminHandler := handlers.NewAdminHandler(userService)
+
+	idempotency := handlers.NewIdempotency(stores.idempotency, cfg.Server.IdempotencyTTL, cfg.Server.IdempotencyLease,
+		cfg.Server.IdempotencyMaxBody, log)
+	go idempotency.Cleanup(backgroundCtx, time.Hour)
+
+	checks := map[string]handlers.ReadinessCheck{}
+	if stores.ready != nil {
+		checks[stores.name] = stores.ready
+	}
+	if publisherReady != nil {
+		checks["events"] = publisherReady
+	}
+
+	// Профили создаются и удаляются по событиям сервиса авторизации
+	var consumer *rabbit.Consumer
+	if cfg.Rabbit.Consumer.Queue != "" {
+		accountEvents := service.NewAccountEvents(userService, log)
+		routingKeys := []string{events.TypeAccountRegistered, events.TypeAccountDeleted}
+		consumer = rabbit.NewConsumer(&cfg.Rabbit, accountEvents, routingKeys, log)
+		if err := consumer.Start(context.Background()); err != nil {
+			log.Error("failed to start rabbit consumer", slog.Any("error", err))
+
+			return
+		}
+		checks["rabbit_consumer"] = consumer.Ready
+	}
+
+	healthHandler := handlers.NewHealthHandler(checks)
+
+	server := handlers.NewServer(userHandler, photoHandler, adminHandler, healthHandler)
+	if err := registerRoutes(e, userService, idempotency, server); err != nil {
+		log.Error("failed to register routes", slog.Any("error", err))
+
+		return
+	}
+
+	// 8. Запуск сервера
+	stopCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverAddr := ":" + strconv.Itoa(cfg.Server.Port)
+	go func() {
+		log.Info("Server started", slog.String("port", serverAddr))
+		if err := e.Start(serverAddr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("Server stopped", slog.Any("error", err))
+			stop()
+		}
+	}()
+
+	<-stopCtx.Done()
+	log.Info("Shutting down")
+
+	// Сначала перестаем брать события, затем дожидаемся HTTP запросов
+	if consumer != nil {
+		if err := consumer.Stop(); err != nil {
+			log.Error("failed to stop rabbit consumer", slog.Any("error", err))
+		}
+	}
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelShutdown()
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		log.Error("failed to shutdown server", slog.Any("error", err))
+	}
+	// Дальше отложенные вызовы: relay досылает outbox, затем закрывается publisher
+}
+
+// newEcho создает сервер с общими middleware. CORS стоит перед аутентификацией: иначе
+// ответы 401 и preflight-запросы уходят без Access-Control-Allow-Origin, и фронтенд
+// вместо статуса видит непрозрачную ошибку CORS.
+func newEcho(log *slog.Logger, verifier *auth.Verifier, apiKeys *auth.APIKeys, validator *handlers.OpenAPIValidator) *echo.Echo {
 	e := echo.New()
+	e.HTTPErrorHandler = handlers.ErrorHandler(log)
+	e.Use(handlers.CorrelationID())
 	e.Use(handlers.Logging(log))
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"http://localhost:3000"},
@@ -118,41 +253,73 @@ func main() {
 			echo.HeaderContentType,
 			echo.HeaderAccept,
 			echo.HeaderAuthorization,
+			handlers.IdempotencyKeyHeader,
+			handlers.CorrelationIDHeader,
 		},
 		AllowCredentials: true,
 	}))
+	e.Use(handlers.Authenticate(verifier, apiKeys))
+	e.Use(validator.Middleware())
+	return e
+}
 
-	// 7. Регистрация маршрутов
-	userHandler := handlers.NewUserHandler(userService)
-	registerRoutes(e, userHandler, photoHandler)
+func registerRoutes(
+	e *echo.Echo,
+	userService *service.UserService,
+	idempotency *handlers.Idempotency,
+	server *handlers.Server,
+) error {
+	// Политики доступа. Principal кладется в контекст глобальным handlers.Authenticate.
+	// Idempotency-Key обрабатывается после проверки доступа, чтобы не запоминать отказы
+	idempotent := idempotency.Middleware()
+	public := []echo.MiddlewareFunc{}
+	owner := []echo.MiddlewareFunc{handlers.OwnerOnly("id"), handlers.NotBanned(userService), idempotent}
+	moderator := []echo.MiddlewareFunc{handlers.RequireRole(auth.RoleAdmin, auth.RoleModerator), idempotent}
+	admin := []echo.MiddlewareFunc{handlers.RequireRole(auth.RoleAdmin), idempotent}
+	// Создание профиля: сервис со скоупом users:create или сам пользователь со своим id
+	creator := []echo.MiddlewareFunc{handlers.RequireServiceScope(auth.ScopeUsersCreate), idempotent}
+	// Поиск профилей: сервис со скоупом users:read, администратор или модератор
+	reader := []echo.MiddlewareFunc{handlers.RequireServiceScopeOrRole(auth.ScopeUsersRead, auth.RoleAdmin, auth.RoleModerator)}
 
-	// 8. Запуск сервера
-	serverAddr := ":" + strconv.Itoa(cfg.Server.Port)
-	log.Info("Server started", slog.String("port", serverAddr))
-	log.Error("Server stopped", slog.Any("error", e.Start(serverAddr)))
-}
+	// Маршруты и обработчики берутся из openapi.yaml (internal/api), здесь только доступ
+	router := handlers.NewPolicyRouter(e, handlers.Policies{
+		"GET /healthy":          public,
+		"GET /ready":            public,
+		"GET /photos/:id":       public,
+		"GET /users/:id":        public,
+		"GET /users/:id/photos": public,
+		"GET /users/:id/tags":   public,
+		"GET /users":            reader,
+		"POST /users:batchGet":  reader,
+		"POST /users":           creator,
+
+		"DELETE /users/:id":                 owner,
+		"PATCH /users/:id/profile":          owner,
+		"PATCH /users/:id/about":            owner, // depricated
+		"PATCH /users/:id/name":             owner, // depricated
+		"PATCH /users/:id/surname":          owner, // depricated
+		"DELETE /users/:id/photos/:photoId": owner,
+		"PATCH /users/:id/primary_photo":    owner,
+		"PUT /users/:id/tag":                owner,
+		"DELETE /users/:id/tags/:tagId":     owner,
+		"POST /users/:id/addphoto":          owner,
+
+		// Администрирование. Все действия пишутся в журнал аудита
+		"GET /admin/users":                        moderator,
+		"GET /admin/users/:id":                    moderator,
+		"PATCH /admin/users/:id":                  admin,
+		"DELETE /admin/users/:id":                 admin,
+		"POST /admin/users/:id/hide":              moderator,
+		"DELETE /admin/users/:id/hide":            moderator,
+		"POST /admin/users/:id/ban":               admin,
+		"DELETE /admin/users/:id/ban":             admin,
+		"DELETE /admin/users/:id/photos/:photoId": moderator,
+		"DELETE /admin/users/:id/tags/:tagId":     moderator,
+		"GET /admin/audit":                        moderator,
+		"GET /admin/search/users":                 admin,
+	})
+
+	api.RegisterHandlers(router, server)
 
-func registerRoutes(e *echo.Echo, userHandler *handlers.UserHandler, photoHandler *handlers.PhotoHandler) {
-	e.POST("/users", userHandler.CreateUser)                     // +
-	e.DELETE("/users/:id", userHandler.DeleteUser)               // ?
-	e.GET("/users/:id", userHandler.GetUserById)                 // +
-	e.PATCH("/users/:id/profile", userHandler.UpdateUserProfile) // +
-	e.PATCH("/users/:id/about", userHandler.UpdateUserAbout)     // depricated
-	e.PATCH("/users/:id/name", userHandler.UpdateUserName)       // depricated
-	e.PATCH("/users/:id/surname", userHandler.UpdateUserSurname) // depricated
-
-	e.GET("/users/:id/photos", userHandler.GetUserPhotos) // +
-	//e.PUT("/users/:id/photos", userHandler.AddUserPhoto)
-	e.DELETE("/users/:id/photos/:photoId", userHandler.RemoveUserPhoto) // +
-	e.PATCH("/users/:id/primary_photo", userHandler.UpdatePrimaryPhoto) // + (айди фото)
-
-	e.PUT("/users/:id/tag", userHandler.AddUserTag)               // +
-	e.GET("/users/:id/tags", userHandler.GetUserTags)             // +
-	e.DELETE("/users/:id/tags/:tagId", userHandler.RemoveUserTag) // +
-
-	// Фото маршруты
-	e.POST("/users/:id/addphoto", photoHandler.UploadPhoto) // + по айди юзера добавляет фотку
-	e.GET("/photos/:id", photoHandler.GetPhoto)             // + по айди !фото! отдает фотку
-
-	e.GET("/healthy", userHandler.Healthy)
+	return router.Check()
 }
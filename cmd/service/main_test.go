@@ -0,0 +1,205 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/auth"
+	"github.com/kerilOvs/profile_sevice/internal/config"
+	"github.com/kerilOvs/profile_sevice/internal/handlers"
+	"github.com/kerilOvs/profile_sevice/internal/service"
+	"github.com/kerilOvs/profile_sevice/internal/storage/memory"
+	"github.com/labstack/echo/v4"
+)
+
+const testAPIKey = "reader-key"
+
+// newTestEcho собирает сервер так же, как main, но поверх хранилища в памяти.
+func newTestEcho(t *testing.T) (*echo.Echo, error) {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	hash := sha256.Sum256([]byte(testAPIKey))
+	apiKeys, err := auth.NewAPIKeys([]config.APIKeyConfig{
+		{Name: "reader", KeyHash: hex.EncodeToString(hash[:]), Scopes: []string{auth.ScopeUsersRead}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := auth.NewVerifier(config.AuthConfig{HMACSecret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userService := service.NewUserService(memory.NewUserMemoryStorage(), config.EventsConfig{})
+	server := handlers.NewServer(
+		handlers.NewUserHandler(userService),
+		handlers.NewPhotoHandler(userService, nil),
+		handlers.NewAdminHandler(userService),
+		handlers.NewHealthHandler(nil),
+	)
+	idempotency := handlers.NewIdempotency(memory.NewIdempotencyMemoryStorage(), time.Hour, time.Minute, 1<<20, log)
+
+	// Ответы тоже проверяем, чтобы каждый тест сверял обработчики с openapi.yaml
+	validator, err := handlers.NewOpenAPIValidator(true, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := newEcho(log, verifier, apiKeys, validator)
+	return e, registerRoutes(e, userService, idempotency, server)
+}
+
+// Каждый маршрут из openapi.yaml должен иметь политику доступа, иначе сервис не стартует
+func TestRegisterRoutesCoversSpec(t *testing.T) {
+	if _, err := newTestEcho(t); err != nil {
+		t.Fatalf("registerRoutes: %v", err)
+	}
+}
+
+func TestBatchGetUsersPolicy(t *testing.T) {
+	e, err := newTestEcho(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		apiKey string
+		want   int
+	}{
+		{"anonymous", "", http.StatusUnauthorized},
+		{"service with users:read", testAPIKey, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/users:batchGet",
+				strings.NewReader(`{"ids":["7d7cf2a4-6c3b-4f43-9a43-3a1e5c6b2f10"]}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.apiKey != "" {
+				req.Header.Set(auth.APIKeyHeader, tt.apiKey)
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
+
+const frontendOrigin = "http://localhost:3000"
+
+// Отказ в доступе должен нести CORS заголовки, иначе браузер скроет статус от фронтенда
+func TestUnauthorizedResponsesCarryCORSHeaders(t *testing.T) {
+	e, err := newTestEcho(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{"no token": "", "invalid token": "Bearer not-a-jwt"} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			req.Header.Set(echo.HeaderOrigin, frontendOrigin)
+			if token != "" {
+				req.Header.Set(echo.HeaderAuthorization, token)
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want 401: %s", rec.Code, rec.Body)
+			}
+			if got := rec.Header().Get(echo.HeaderAccessControlAllowOrigin); got != frontendOrigin {
+				t.Fatalf("Access-Control-Allow-Origin = %q, want %q", got, frontendOrigin)
+			}
+		})
+	}
+}
+
+func TestPreflightSkipsAuthentication(t *testing.T) {
+	e, err := newTestEcho(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodOptions, "/users/7d7cf2a4-6c3b-4f43-9a43-3a1e5c6b2f10/profile", nil)
+	req.Header.Set(echo.HeaderOrigin, frontendOrigin)
+	req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodPatch)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer expired-or-invalid")
+	rec := httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204: %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get(echo.HeaderAccessControlAllowOrigin); got != frontendOrigin {
+		t.Fatalf("Access-Control-Allow-Origin = %q, want %q", got, frontendOrigin)
+	}
+	if got := rec.Header().Get(echo.HeaderAccessControlAllowMethods); !strings.Contains(got, http.MethodPatch) {
+		t.Fatalf("Access-Control-Allow-Methods = %q", got)
+	}
+}
+
+// userToken подписывает JWT пользователя секретом из newTestEcho.
+func userToken(t *testing.T, roles ...string) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   uuid.NewString(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": roles,
+	}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + token
+}
+
+// Поиск отдает скрытые и забаненные профили, поэтому доступен только администраторам
+func TestAdminSearchRequiresAdmin(t *testing.T) {
+	e, err := newTestEcho(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{"anonymous", "", "", http.StatusUnauthorized},
+		{"user", echo.HeaderAuthorization, userToken(t), http.StatusForbidden},
+		{"moderator", echo.HeaderAuthorization, userToken(t, auth.RoleModerator), http.StatusForbidden},
+		{"service with users:read", auth.APIKeyHeader, testAPIKey, http.StatusForbidden},
+		{"admin", echo.HeaderAuthorization, userToken(t, auth.RoleAdmin), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/search/users?q=ivan", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want == http.StatusForbidden && !strings.Contains(rec.Body.String(), `"code":"insufficient_permissions"`) {
+				t.Fatalf("body = %s, want insufficient_permissions", rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/kerilOvs/profile_sevice/internal/config"
+	"github.com/kerilOvs/profile_sevice/internal/events"
+	"github.com/kerilOvs/profile_sevice/internal/handlers"
+	"github.com/kerilOvs/profile_sevice/internal/storage/nats"
+	"github.com/kerilOvs/profile_sevice/internal/storage/rabbit"
+)
+
+// recorderLimit - сколько последних событий держит в памяти publisher "memory".
+const recorderLimit = 1000
+
+// newPublisher создает выбранный в конфиге EventPublisher. ready - проверка готовности
+// брокера для /ready, nil если брокера нет.
+func newPublisher(ctx context.Context, cfg config.Config, log *slog.Logger) (publisher events.EventPublisher, ready handlers.ReadinessCheck, err error) {
+	switch cfg.Events.Publisher {
+	case "", "rabbit":
+		log.Info("Connecting to Rabbit")
+		repo, err := rabbit.New(ctx, &cfg.Rabbit, cfg.Events, log)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create rabbit_repo: %w", err)
+		}
+		return repo, repo.Ready, nil
+	case "nats":
+		log.Info("Connecting to NATS")
+		repo, err := nats.New(ctx, cfg.NATS, log)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create nats repo: %w", err)
+		}
+		return repo, repo.Ready, nil
+	case "memory":
+		log.Warn("Events are not sent to a broker, only logged")
+		return events.NewRecorder(recorderLimit, log.WithGroup("events")), nil, nil
+	}
+	return nil, nil, fmt.Errorf("unknown events publisher %q", cfg.Events.Publisher)
+}
+
+// closePublisher закрывает соединение с брокером. Встроенный NATS при этом останавливается
+// и удаляет временный каталог JetStream.
+func closePublisher(publisher events.EventPublisher, log *slog.Logger) {
+	closer, ok := publisher.(io.Closer)
+	if !ok {
+		return
+	}
+	if err := closer.Close(); err != nil {
+		log.Error("failed to close events publisher", slog.Any("error", err))
+	}
+}
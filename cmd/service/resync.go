@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/service"
+)
+
+// runResync - подкоманда resync: заново ставит в outbox анкеты, теги и главные фото всех
+// пользователей. Отправляет их relay работающего сервиса. -rate ограничивает только запись
+// в outbox: накопившиеся сообщения relay публикует с той скоростью, с какой их примет брокер.
+//
+//	service resync [-after <uuid>] [-batch 500] [-rate 200] [-dry-run]
+func runResync(ctx context.Context, userService *service.UserService, args []string, log *slog.Logger) error {
+	flags := flag.NewFlagSet("resync", flag.ContinueOnError)
+	after := flags.String("after", "", "resume after this user id (cursor from the previous run)")
+	batch := flags.Int("batch", 500, "users per transaction")
+	rate := flags.Int("rate", 200, "max users written to the outbox per second, 0 for no limit")
+	dryRun := flags.Bool("dry-run", false, "count events without writing them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	opts := service.ResyncOptions{
+		BatchSize: *batch,
+		Rate:      *rate,
+		DryRun:    *dryRun,
+		Progress: func(p service.ResyncProgress) {
+			log.Info("resync progress", slog.String("cursor", p.Cursor.String()), slog.Int("users", p.Users), slog.Int("events", p.Events))
+		},
+	}
+	if *after != "" {
+		cursor, err := uuid.Parse(*after)
+		if err != nil {
+			return fmt.Errorf("invalid -after: %w", err)
+		}
+		opts.After = cursor
+	}
+
+	log.Info("resync started", slog.String("after", opts.After.String()), slog.Bool("dry_run", opts.DryRun))
+	progress, err := userService.Resync(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("resync stopped, resume with -after=%s: %w", progress.Cursor, err)
+	}
+
+	log.Info("resync finished", slog.Int("users", progress.Users), slog.Int("events", progress.Events))
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/kerilOvs/profile_sevice/internal/config"
+	"github.com/kerilOvs/profile_sevice/internal/handlers"
+	"github.com/kerilOvs/profile_sevice/internal/migrations"
+	"github.com/kerilOvs/profile_sevice/internal/storage"
+	"github.com/kerilOvs/profile_sevice/internal/storage/memory"
+	postgresstorage "github.com/kerilOvs/profile_sevice/internal/storage/postgres"
+)
+
+// storages - хранилища выбранного в конфиге бэкенда.
+type storages struct {
+	users       storage.UserStorage
+	outbox      storage.OutboxStorage
+	idempotency storage.IdempotencyStorage
+
+	// name и ready - проверка готовности для /ready, ready == nil если проверять нечего
+	name  string
+	ready handlers.ReadinessCheck
+	// migrator есть только у postgres
+	migrator *migrations.Migrator
+}
+
+// newStorage создает хранилища по cfg.Storage.Backend: "postgres" или "memory".
+func newStorage(cfg config.Config, log *slog.Logger) (storages, error) {
+	switch cfg.Storage.Backend {
+	case "", "postgres":
+		return newPostgresStorage(cfg.Database, log)
+	case "memory":
+		log.Warn("Profiles are kept in memory and lost on restart")
+		users := memory.NewUserMemoryStorage()
+		return storages{
+			users:       users,
+			outbox:      memory.NewOutboxMemoryStorage(users),
+			idempotency: memory.NewIdempotencyMemoryStorage(),
+			name:        "memory",
+		}, nil
+	}
+	return storages{}, fmt.Errorf("unknown storage backend %q", cfg.Storage.Backend)
+}
+
+func newPostgresStorage(cfg config.DBConfig, log *slog.Logger) (storages, error) {
+	log.Info("Connecting to db...")
+	dsn := fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
+		cfg.Host,
+		cfg.User,
+		cfg.Password,
+		cfg.Dbname,
+		strconv.Itoa(cfg.Port),
+	)
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
+	if err != nil {
+		log.Error("Failed to connect to database:", slog.Any("error", err))
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		panic("failed to get underlying sql.DB")
+	}
+
+	// Настройка пула соединений
+	sqlDB.SetMaxIdleConns(10)           // Максимальное количество бездействующих соединений
+	sqlDB.SetMaxOpenConns(100)          // Максимальное количество открытых соединений
+	sqlDB.SetConnMaxLifetime(time.Hour) // Максимальное время жизни соединения
+
+	migrator, err := migrations.New(sqlDB, log)
+	if err != nil {
+		return storages{}, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	return storages{
+		users:       postgresstorage.NewUserPostgresStorage(db),
+		outbox:      postgresstorage.NewOutboxPostgresStorage(db),
+		idempotency: postgresstorage.NewIdempotencyPostgresStorage(db),
+		name:        "postgres",
+		ready:       sqlDB.PingContext,
+		migrator:    migrator,
+	}, nil
+}
@@ -0,0 +1,5 @@
+// Package api содержит сервер и модели, сгенерированные из openapi.yaml.
+// После изменения спецификации нужно выполнить go generate ./internal/api
+package api
+
+//go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen@v2.4.1 -config oapi-codegen.yaml ../../openapi.yaml
@@ -0,0 +1,1777 @@
+// Package api provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.4.1 DO NOT EDIT.
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/kerilOvs/profile_sevice/internal/models"
+	"github.com/labstack/echo/v4"
+	"github.com/oapi-codegen/runtime"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+const (
+	ApiKeyAuthScopes = "apiKeyAuth.Scopes"
+	BearerAuthScopes = "bearerAuth.Scopes"
+)
+
+// Defines values for FieldErrorIn.
+const (
+	Body   FieldErrorIn = "body"
+	Header FieldErrorIn = "header"
+	Path   FieldErrorIn = "path"
+	Query  FieldErrorIn = "query"
+)
+
+// Defines values for ReadinessStatus.
+const (
+	Ok          ReadinessStatus = "ok"
+	Unavailable ReadinessStatus = "unavailable"
+)
+
+// Defines values for ListUsersParamsTagsMode.
+const (
+	All ListUsersParamsTagsMode = "all"
+	Any ListUsersParamsTagsMode = "any"
+)
+
+// AboutUpdate defines model for AboutUpdate.
+type AboutUpdate struct {
+	AboutMyself string `json:"about_myself"`
+}
+
+// AuditRecord defines model for AuditRecord.
+type AuditRecord = models.AuditRecord
+
+// BanRequest defines model for BanRequest.
+type BanRequest struct {
+	Reason *string `json:"reason,omitempty"`
+}
+
+// FieldError defines model for FieldError.
+type FieldError struct {
+	// Field Name of the parameter or path of the field inside the body, e.g. gender
+	Field   string       `json:"field"`
+	In      FieldErrorIn `json:"in"`
+	Message string       `json:"message"`
+}
+
+// FieldErrorIn defines model for FieldError.In.
+type FieldErrorIn string
+
+// Gender User's gender identity
+type Gender = models.UserGender
+
+// NameUpdate defines model for NameUpdate.
+type NameUpdate struct {
+	Name string `json:"name"`
+}
+
+// PrimaryPhotoUpdate defines model for PrimaryPhotoUpdate.
+type PrimaryPhotoUpdate struct {
+	Id openapi_types.UUID `json:"id"`
+}
+
+// Problem RFC 7807 problem details
+type Problem struct {
+	// Code Stable machine-readable error code, e.g. user_not_found
+	Code string `json:"code"`
+
+	// Detail Human-readable explanation of this occurrence
+	Detail *string `json:"detail,omitempty"`
+
+	// Fields Field-level validation errors
+	Fields *[]FieldError `json:"fields,omitempty"`
+
+	// Instance Request path the problem occurred on
+	Instance *string `json:"instance,omitempty"`
+
+	// Status HTTP status code
+	Status int `json:"status"`
+
+	// Title Short human-readable summary
+	Title string `json:"title"`
+
+	// Type URI identifying the problem type
+	Type string `json:"type"`
+}
+
+// Readiness defines model for Readiness.
+type Readiness struct {
+	// Checks State of each dependency, "ok" or the error text
+	Checks map[string]string `json:"checks"`
+	Status ReadinessStatus   `json:"status"`
+}
+
+// ReadinessStatus defines model for Readiness.Status.
+type ReadinessStatus string
+
+// SurnameUpdate defines model for SurnameUpdate.
+type SurnameUpdate struct {
+	Surname string `json:"surname"`
+}
+
+// TagAdd defines model for TagAdd.
+type TagAdd struct {
+	Category *string `json:"category,omitempty"`
+	Tag      string  `json:"tag"`
+}
+
+// User defines model for User.
+type User = models.User
+
+// UserBatch defines model for UserBatch.
+type UserBatch struct {
+	// Missing Requested ids without a visible profile
+	Missing []openapi_types.UUID `json:"missing"`
+	Users   []UserSummary        `json:"users"`
+}
+
+// UserBatchGet defines model for UserBatchGet.
+type UserBatchGet struct {
+	Ids []openapi_types.UUID `json:"ids"`
+}
+
+// UserCreate defines model for UserCreate.
+type UserCreate struct {
+	// AboutMyself User's self-description
+	AboutMyself *string `json:"about_myself,omitempty"`
+
+	// Gender User's gender identity
+	Gender *Gender `json:"gender,omitempty"`
+
+	// Id Id of the account in the auth service
+	Id openapi_types.UUID `json:"id"`
+
+	// Name User's first name
+	Name string `json:"name"`
+
+	// Surname User's last name
+	Surname string `json:"surname"`
+}
+
+// UserPage defines model for UserPage.
+type UserPage struct {
+	Items []User `json:"items"`
+
+	// NextCursor Cursor of the next page, absent on the last page
+	NextCursor *string `json:"next_cursor,omitempty"`
+}
+
+// UserPhoto defines model for UserPhoto.
+type UserPhoto = models.UserPhoto
+
+// UserProfileUpdate defines model for UserProfileUpdate.
+type UserProfileUpdate = models.UserProfileUpdate
+
+// UserSearchHit defines model for UserSearchHit.
+type UserSearchHit = models.UserSearchHit
+
+// UserSummary Compact profile for feeds and cards
+type UserSummary = models.UserSummary
+
+// UserTag defines model for UserTag.
+type UserTag = models.UserTag
+
+// Cursor defines model for cursor.
+type Cursor = string
+
+// IdempotencyKey defines model for idempotencyKey.
+type IdempotencyKey = openapi_types.UUID
+
+// Limit defines model for limit.
+type Limit = int
+
+// Offset defines model for offset.
+type Offset = int
+
+// PhotoId defines model for photoId.
+type PhotoId = openapi_types.UUID
+
+// TagId defines model for tagId.
+type TagId = openapi_types.UUID
+
+// UserId defines model for userId.
+type UserId = openapi_types.UUID
+
+// AdminListAuditRecordsParams defines parameters for AdminListAuditRecords.
+type AdminListAuditRecordsParams struct {
+	// UserId Only records about this user
+	UserId *openapi_types.UUID `form:"user_id,omitempty" json:"user_id,omitempty"`
+	Offset *Offset             `form:"offset,omitempty" json:"offset,omitempty"`
+	Limit  *Limit              `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// AdminSearchUsersParams defines parameters for AdminSearchUsers.
+type AdminSearchUsersParams struct {
+	Q      string  `form:"q" json:"q"`
+	Offset *Offset `form:"offset,omitempty" json:"offset,omitempty"`
+	Limit  *Limit  `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// AdminListUsersParams defines parameters for AdminListUsers.
+type AdminListUsersParams struct {
+	Offset *Offset `form:"offset,omitempty" json:"offset,omitempty"`
+	Limit  *Limit  `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// AdminDeleteUserParams defines parameters for AdminDeleteUser.
+type AdminDeleteUserParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// AdminUpdateUserParams defines parameters for AdminUpdateUser.
+type AdminUpdateUserParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// AdminUnbanUserParams defines parameters for AdminUnbanUser.
+type AdminUnbanUserParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// AdminBanUserParams defines parameters for AdminBanUser.
+type AdminBanUserParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// AdminUnhideUserParams defines parameters for AdminUnhideUser.
+type AdminUnhideUserParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// AdminHideUserParams defines parameters for AdminHideUser.
+type AdminHideUserParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// AdminRemoveUserPhotoParams defines parameters for AdminRemoveUserPhoto.
+type AdminRemoveUserPhotoParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// AdminRemoveUserTagParams defines parameters for AdminRemoveUserTag.
+type AdminRemoveUserTagParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// ListUsersParams defines parameters for ListUsers.
+type ListUsersParams struct {
+	Gender *Gender `form:"gender,omitempty" json:"gender,omitempty"`
+
+	// AgeMin Minimum age in full years, computed from birth_date
+	AgeMin *int `form:"age_min,omitempty" json:"age_min,omitempty"`
+
+	// AgeMax Maximum age in full years, computed from birth_date
+	AgeMax *int `form:"age_max,omitempty" json:"age_max,omitempty"`
+
+	// JungResult Jung personality type, e.g. INTJ
+	JungResult *string `form:"jung_result,omitempty" json:"jung_result,omitempty"`
+
+	// Tags Tag values, repeat the parameter for several tags
+	Tags *[]string `form:"tags,omitempty" json:"tags,omitempty"`
+
+	// TagsMode Match users with any of the tags (default) or with all of them
+	TagsMode *ListUsersParamsTagsMode `form:"tags_mode,omitempty" json:"tags_mode,omitempty"`
+
+	// CreatedAfter Only users created at or after this time
+	CreatedAfter *time.Time `form:"created_after,omitempty" json:"created_after,omitempty"`
+
+	// CreatedBefore Only users created before this time
+	CreatedBefore *time.Time `form:"created_before,omitempty" json:"created_before,omitempty"`
+	IncludeHidden *bool      `form:"include_hidden,omitempty" json:"include_hidden,omitempty"`
+
+	// Cursor next_cursor from the previous page
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+	Limit  *Limit  `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// ListUsersParamsTagsMode defines parameters for ListUsers.
+type ListUsersParamsTagsMode string
+
+// CreateUserParams defines parameters for CreateUser.
+type CreateUserParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// DeleteUserParams defines parameters for DeleteUser.
+type DeleteUserParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// UpdateUserAboutParams defines parameters for UpdateUserAbout.
+type UpdateUserAboutParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// UploadPhotoMultipartBody defines parameters for UploadPhoto.
+type UploadPhotoMultipartBody struct {
+	// Photo JPEG or PNG image
+	Photo openapi_types.File `json:"photo"`
+}
+
+// UploadPhotoParams defines parameters for UploadPhoto.
+type UploadPhotoParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// UpdateUserNameParams defines parameters for UpdateUserName.
+type UpdateUserNameParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// RemoveUserPhotoParams defines parameters for RemoveUserPhoto.
+type RemoveUserPhotoParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// UpdatePrimaryPhotoParams defines parameters for UpdatePrimaryPhoto.
+type UpdatePrimaryPhotoParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// UpdateUserProfileParams defines parameters for UpdateUserProfile.
+type UpdateUserProfileParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// UpdateUserSurnameParams defines parameters for UpdateUserSurname.
+type UpdateUserSurnameParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// AddUserTagParams defines parameters for AddUserTag.
+type AddUserTagParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// RemoveUserTagParams defines parameters for RemoveUserTag.
+type RemoveUserTagParams struct {
+	// IdempotencyKey Key to make the request idempotent. A repeat with the same key and body returns the stored response with Idempotent-Replayed: true; a different body is rejected with 422. While the first request runs, repeats get 409. A request that never completed frees its key after a short lease. Bodies over the configured limit are rejected with 413.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// AdminUpdateUserJSONRequestBody defines body for AdminUpdateUser for application/json ContentType.
+type AdminUpdateUserJSONRequestBody = UserProfileUpdate
+
+// AdminBanUserJSONRequestBody defines body for AdminBanUser for application/json ContentType.
+type AdminBanUserJSONRequestBody = BanRequest
+
+// CreateUserJSONRequestBody defines body for CreateUser for application/json ContentType.
+type CreateUserJSONRequestBody = UserCreate
+
+// UpdateUserAboutJSONRequestBody defines body for UpdateUserAbout for application/json ContentType.
+type UpdateUserAboutJSONRequestBody = AboutUpdate
+
+// UploadPhotoMultipartRequestBody defines body for UploadPhoto for multipart/form-data ContentType.
+type UploadPhotoMultipartRequestBody UploadPhotoMultipartBody
+
+// UpdateUserNameJSONRequestBody defines body for UpdateUserName for application/json ContentType.
+type UpdateUserNameJSONRequestBody = NameUpdate
+
+// UpdatePrimaryPhotoJSONRequestBody defines body for UpdatePrimaryPhoto for application/json ContentType.
+type UpdatePrimaryPhotoJSONRequestBody = PrimaryPhotoUpdate
+
+// UpdateUserProfileJSONRequestBody defines body for UpdateUserProfile for application/json ContentType.
+type UpdateUserProfileJSONRequestBody = UserProfileUpdate
+
+// UpdateUserSurnameJSONRequestBody defines body for UpdateUserSurname for application/json ContentType.
+type UpdateUserSurnameJSONRequestBody = SurnameUpdate
+
+// AddUserTagJSONRequestBody defines body for AddUserTag for application/json ContentType.
+type AddUserTagJSONRequestBody = TagAdd
+
+// BatchGetUsersJSONRequestBody defines body for BatchGetUsers for application/json ContentType.
+type BatchGetUsersJSONRequestBody = UserBatchGet
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// List audit records of admin and moderator actions
+	// (GET /admin/audit)
+	AdminListAuditRecords(ctx echo.Context, params AdminListAuditRecordsParams) error
+	// Full-text search over names, about_myself and tags
+	// (GET /admin/search/users)
+	AdminSearchUsers(ctx echo.Context, params AdminSearchUsersParams) error
+	// List all users, including hidden and banned
+	// (GET /admin/users)
+	AdminListUsers(ctx echo.Context, params AdminListUsersParams) error
+	// Delete any user (admin only)
+	// (DELETE /admin/users/{id})
+	AdminDeleteUser(ctx echo.Context, id UserId, params AdminDeleteUserParams) error
+	// Get any user by ID
+	// (GET /admin/users/{id})
+	AdminGetUser(ctx echo.Context, id UserId) error
+	// Update any user's profile (admin only)
+	// (PATCH /admin/users/{id})
+	AdminUpdateUser(ctx echo.Context, id UserId, params AdminUpdateUserParams) error
+	// Lift a ban (admin only)
+	// (DELETE /admin/users/{id}/ban)
+	AdminUnbanUser(ctx echo.Context, id UserId, params AdminUnbanUserParams) error
+	// Ban an account (admin only)
+	// (POST /admin/users/{id}/ban)
+	AdminBanUser(ctx echo.Context, id UserId, params AdminBanUserParams) error
+	// Make a hidden profile visible again
+	// (DELETE /admin/users/{id}/hide)
+	AdminUnhideUser(ctx echo.Context, id UserId, params AdminUnhideUserParams) error
+	// Hide a profile from other users
+	// (POST /admin/users/{id}/hide)
+	AdminHideUser(ctx echo.Context, id UserId, params AdminHideUserParams) error
+	// Force-remove a photo
+	// (DELETE /admin/users/{id}/photos/{photoId})
+	AdminRemoveUserPhoto(ctx echo.Context, id UserId, photoId PhotoId, params AdminRemoveUserPhotoParams) error
+	// Force-remove a tag
+	// (DELETE /admin/users/{id}/tags/{tagId})
+	AdminRemoveUserTag(ctx echo.Context, id UserId, tagId TagId, params AdminRemoveUserTagParams) error
+	// Health check
+	// (GET /healthy)
+	Healthy(ctx echo.Context) error
+	// Get photo by object name
+	// (GET /photos/{id})
+	GetPhoto(ctx echo.Context, id string) error
+	// Readiness check
+	// (GET /ready)
+	Ready(ctx echo.Context) error
+	// List and filter users
+	// (GET /users)
+	ListUsers(ctx echo.Context, params ListUsersParams) error
+	// Create a new user
+	// (POST /users)
+	CreateUser(ctx echo.Context, params CreateUserParams) error
+	// Delete a user
+	// (DELETE /users/{id})
+	DeleteUser(ctx echo.Context, id UserId, params DeleteUserParams) error
+	// Get user by ID
+	// (GET /users/{id})
+	GetUserById(ctx echo.Context, id UserId) error
+	// Update user's about section
+	// (PATCH /users/{id}/about)
+	UpdateUserAbout(ctx echo.Context, id UserId, params UpdateUserAboutParams) error
+	// Upload a photo to user's profile
+	// (POST /users/{id}/addphoto)
+	UploadPhoto(ctx echo.Context, id UserId, params UploadPhotoParams) error
+	// Update user's name
+	// (PATCH /users/{id}/name)
+	UpdateUserName(ctx echo.Context, id UserId, params UpdateUserNameParams) error
+	// Get user's photos
+	// (GET /users/{id}/photos)
+	GetUserPhotos(ctx echo.Context, id UserId) error
+	// Remove a photo from user's profile
+	// (DELETE /users/{id}/photos/{photoId})
+	RemoveUserPhoto(ctx echo.Context, id UserId, photoId PhotoId, params RemoveUserPhotoParams) error
+	// Update user's primary photo
+	// (PATCH /users/{id}/primary_photo)
+	UpdatePrimaryPhoto(ctx echo.Context, id UserId, params UpdatePrimaryPhotoParams) error
+	// Update user's profile
+	// (PATCH /users/{id}/profile)
+	UpdateUserProfile(ctx echo.Context, id UserId, params UpdateUserProfileParams) error
+	// Update user's surname
+	// (PATCH /users/{id}/surname)
+	UpdateUserSurname(ctx echo.Context, id UserId, params UpdateUserSurnameParams) error
+	// Add a tag to user's profile
+	// (PUT /users/{id}/tag)
+	AddUserTag(ctx echo.Context, id UserId, params AddUserTagParams) error
+	// Get user's tags
+	// (GET /users/{id}/tags)
+	GetUserTags(ctx echo.Context, id UserId) error
+	// Remove a tag from user's profile
+	// (DELETE /users/{id}/tags/{tagId})
+	RemoveUserTag(ctx echo.Context, id UserId, tagId TagId, params RemoveUserTagParams) error
+	// Get several profiles in one request
+	// (POST /users:batchGet)
+	BatchGetUsers(ctx echo.Context) error
+}
+
+// ServerInterfaceWrapper converts echo contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler ServerInterface
+}
+
+// AdminListAuditRecords converts echo context to params.
+func (w *ServerInterfaceWrapper) AdminListAuditRecords(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AdminListAuditRecordsParams
+	// ------------- Optional query parameter "user_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "user_id", ctx.QueryParams(), &params.UserId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter user_id: %s", err))
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", ctx.QueryParams(), &params.Offset)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter offset: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.AdminListAuditRecords(ctx, params)
+	return err
+}
+
+// AdminSearchUsers converts echo context to params.
+func (w *ServerInterfaceWrapper) AdminSearchUsers(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AdminSearchUsersParams
+	// ------------- Required query parameter "q" -------------
+
+	err = runtime.BindQueryParameter("form", true, true, "q", ctx.QueryParams(), &params.Q)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter q: %s", err))
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", ctx.QueryParams(), &params.Offset)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter offset: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.AdminSearchUsers(ctx, params)
+	return err
+}
+
+// AdminListUsers converts echo context to params.
+func (w *ServerInterfaceWrapper) AdminListUsers(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AdminListUsersParams
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", ctx.QueryParams(), &params.Offset)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter offset: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.AdminListUsers(ctx, params)
+	return err
+}
+
+// AdminDeleteUser converts echo context to params.
+func (w *ServerInterfaceWrapper) AdminDeleteUser(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AdminDeleteUserParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.AdminDeleteUser(ctx, id, params)
+	return err
+}
+
+// AdminGetUser converts echo context to params.
+func (w *ServerInterfaceWrapper) AdminGetUser(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.AdminGetUser(ctx, id)
+	return err
+}
+
+// AdminUpdateUser converts echo context to params.
+func (w *ServerInterfaceWrapper) AdminUpdateUser(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AdminUpdateUserParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.AdminUpdateUser(ctx, id, params)
+	return err
+}
+
+// AdminUnbanUser converts echo context to params.
+func (w *ServerInterfaceWrapper) AdminUnbanUser(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AdminUnbanUserParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.AdminUnbanUser(ctx, id, params)
+	return err
+}
+
+// AdminBanUser converts echo context to params.
+func (w *ServerInterfaceWrapper) AdminBanUser(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AdminBanUserParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.AdminBanUser(ctx, id, params)
+	return err
+}
+
+// AdminUnhideUser converts echo context to params.
+func (w *ServerInterfaceWrapper) AdminUnhideUser(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AdminUnhideUserParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.AdminUnhideUser(ctx, id, params)
+	return err
+}
+
+// AdminHideUser converts echo context to params.
+func (w *ServerInterfaceWrapper) AdminHideUser(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AdminHideUserParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.AdminHideUser(ctx, id, params)
+	return err
+}
+
+// AdminRemoveUserPhoto converts echo context to params.
+func (w *ServerInterfaceWrapper) AdminRemoveUserPhoto(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// ------------- Path parameter "photoId" -------------
+	var photoId PhotoId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "photoId", ctx.Param("photoId"), &photoId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter photoId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AdminRemoveUserPhotoParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.AdminRemoveUserPhoto(ctx, id, photoId, params)
+	return err
+}
+
+// AdminRemoveUserTag converts echo context to params.
+func (w *ServerInterfaceWrapper) AdminRemoveUserTag(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// ------------- Path parameter "tagId" -------------
+	var tagId TagId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tagId", ctx.Param("tagId"), &tagId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter tagId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AdminRemoveUserTagParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.AdminRemoveUserTag(ctx, id, tagId, params)
+	return err
+}
+
+// Healthy converts echo context to params.
+func (w *ServerInterfaceWrapper) Healthy(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.Healthy(ctx)
+	return err
+}
+
+// GetPhoto converts echo context to params.
+func (w *ServerInterfaceWrapper) GetPhoto(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetPhoto(ctx, id)
+	return err
+}
+
+// Ready converts echo context to params.
+func (w *ServerInterfaceWrapper) Ready(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.Ready(ctx)
+	return err
+}
+
+// ListUsers converts echo context to params.
+func (w *ServerInterfaceWrapper) ListUsers(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListUsersParams
+	// ------------- Optional query parameter "gender" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "gender", ctx.QueryParams(), &params.Gender)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter gender: %s", err))
+	}
+
+	// ------------- Optional query parameter "age_min" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "age_min", ctx.QueryParams(), &params.AgeMin)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter age_min: %s", err))
+	}
+
+	// ------------- Optional query parameter "age_max" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "age_max", ctx.QueryParams(), &params.AgeMax)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter age_max: %s", err))
+	}
+
+	// ------------- Optional query parameter "jung_result" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "jung_result", ctx.QueryParams(), &params.JungResult)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter jung_result: %s", err))
+	}
+
+	// ------------- Optional query parameter "tags" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "tags", ctx.QueryParams(), &params.Tags)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter tags: %s", err))
+	}
+
+	// ------------- Optional query parameter "tags_mode" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "tags_mode", ctx.QueryParams(), &params.TagsMode)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter tags_mode: %s", err))
+	}
+
+	// ------------- Optional query parameter "created_after" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "created_after", ctx.QueryParams(), &params.CreatedAfter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter created_after: %s", err))
+	}
+
+	// ------------- Optional query parameter "created_before" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "created_before", ctx.QueryParams(), &params.CreatedBefore)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter created_before: %s", err))
+	}
+
+	// ------------- Optional query parameter "include_hidden" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "include_hidden", ctx.QueryParams(), &params.IncludeHidden)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter include_hidden: %s", err))
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListUsers(ctx, params)
+	return err
+}
+
+// CreateUser converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateUser(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CreateUserParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CreateUser(ctx, params)
+	return err
+}
+
+// DeleteUser converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteUser(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteUserParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.DeleteUser(ctx, id, params)
+	return err
+}
+
+// GetUserById converts echo context to params.
+func (w *ServerInterfaceWrapper) GetUserById(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetUserById(ctx, id)
+	return err
+}
+
+// UpdateUserAbout converts echo context to params.
+func (w *ServerInterfaceWrapper) UpdateUserAbout(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params UpdateUserAboutParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.UpdateUserAbout(ctx, id, params)
+	return err
+}
+
+// UploadPhoto converts echo context to params.
+func (w *ServerInterfaceWrapper) UploadPhoto(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params UploadPhotoParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.UploadPhoto(ctx, id, params)
+	return err
+}
+
+// UpdateUserName converts echo context to params.
+func (w *ServerInterfaceWrapper) UpdateUserName(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params UpdateUserNameParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.UpdateUserName(ctx, id, params)
+	return err
+}
+
+// GetUserPhotos converts echo context to params.
+func (w *ServerInterfaceWrapper) GetUserPhotos(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetUserPhotos(ctx, id)
+	return err
+}
+
+// RemoveUserPhoto converts echo context to params.
+func (w *ServerInterfaceWrapper) RemoveUserPhoto(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// ------------- Path parameter "photoId" -------------
+	var photoId PhotoId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "photoId", ctx.Param("photoId"), &photoId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter photoId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params RemoveUserPhotoParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RemoveUserPhoto(ctx, id, photoId, params)
+	return err
+}
+
+// UpdatePrimaryPhoto converts echo context to params.
+func (w *ServerInterfaceWrapper) UpdatePrimaryPhoto(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params UpdatePrimaryPhotoParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.UpdatePrimaryPhoto(ctx, id, params)
+	return err
+}
+
+// UpdateUserProfile converts echo context to params.
+func (w *ServerInterfaceWrapper) UpdateUserProfile(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params UpdateUserProfileParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.UpdateUserProfile(ctx, id, params)
+	return err
+}
+
+// UpdateUserSurname converts echo context to params.
+func (w *ServerInterfaceWrapper) UpdateUserSurname(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params UpdateUserSurnameParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.UpdateUserSurname(ctx, id, params)
+	return err
+}
+
+// AddUserTag converts echo context to params.
+func (w *ServerInterfaceWrapper) AddUserTag(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AddUserTagParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.AddUserTag(ctx, id, params)
+	return err
+}
+
+// GetUserTags converts echo context to params.
+func (w *ServerInterfaceWrapper) GetUserTags(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetUserTags(ctx, id)
+	return err
+}
+
+// RemoveUserTag converts echo context to params.
+func (w *ServerInterfaceWrapper) RemoveUserTag(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id UserId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// ------------- Path parameter "tagId" -------------
+	var tagId TagId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "tagId", ctx.Param("tagId"), &tagId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter tagId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params RemoveUserTagParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Idempotency-Key, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Idempotency-Key: %s", err))
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RemoveUserTag(ctx, id, tagId, params)
+	return err
+}
+
+// BatchGetUsers converts echo context to params.
+func (w *ServerInterfaceWrapper) BatchGetUsers(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(ApiKeyAuthScopes, []string{})
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.BatchGetUsers(ctx)
+	return err
+}
+
+// This is a simple interface which specifies echo.Route addition functions which
+// are present on both echo.Echo and echo.Group, since we want to allow using
+// either of them for path registration
+type EchoRouter interface {
+	CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	HEAD(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	OPTIONS(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+}
+
+// RegisterHandlers adds each server route to the EchoRouter.
+func RegisterHandlers(router EchoRouter, si ServerInterface) {
+	RegisterHandlersWithBaseURL(router, si, "")
+}
+
+// Registers handlers, and prepends BaseURL to the paths, so that the paths
+// can be served under a prefix.
+func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL string) {
+
+	wrapper := ServerInterfaceWrapper{
+		Handler: si,
+	}
+
+	router.GET(baseURL+"/admin/audit", wrapper.AdminListAuditRecords)
+	router.GET(baseURL+"/admin/search/users", wrapper.AdminSearchUsers)
+	router.GET(baseURL+"/admin/users", wrapper.AdminListUsers)
+	router.DELETE(baseURL+"/admin/users/:id", wrapper.AdminDeleteUser)
+	router.GET(baseURL+"/admin/users/:id", wrapper.AdminGetUser)
+	router.PATCH(baseURL+"/admin/users/:id", wrapper.AdminUpdateUser)
+	router.DELETE(baseURL+"/admin/users/:id/ban", wrapper.AdminUnbanUser)
+	router.POST(baseURL+"/admin/users/:id/ban", wrapper.AdminBanUser)
+	router.DELETE(baseURL+"/admin/users/:id/hide", wrapper.AdminUnhideUser)
+	router.POST(baseURL+"/admin/users/:id/hide", wrapper.AdminHideUser)
+	router.DELETE(baseURL+"/admin/users/:id/photos/:photoId", wrapper.AdminRemoveUserPhoto)
+	router.DELETE(baseURL+"/admin/users/:id/tags/:tagId", wrapper.AdminRemoveUserTag)
+	router.GET(baseURL+"/healthy", wrapper.Healthy)
+	router.GET(baseURL+"/photos/:id", wrapper.GetPhoto)
+	router.GET(baseURL+"/ready", wrapper.Ready)
+	router.GET(baseURL+"/users", wrapper.ListUsers)
+	router.POST(baseURL+"/users", wrapper.CreateUser)
+	router.DELETE(baseURL+"/users/:id", wrapper.DeleteUser)
+	router.GET(baseURL+"/users/:id", wrapper.GetUserById)
+	router.PATCH(baseURL+"/users/:id/about", wrapper.UpdateUserAbout)
+	router.POST(baseURL+"/users/:id/addphoto", wrapper.UploadPhoto)
+	router.PATCH(baseURL+"/users/:id/name", wrapper.UpdateUserName)
+	router.GET(baseURL+"/users/:id/photos", wrapper.GetUserPhotos)
+	router.DELETE(baseURL+"/users/:id/photos/:photoId", wrapper.RemoveUserPhoto)
+	router.PATCH(baseURL+"/users/:id/primary_photo", wrapper.UpdatePrimaryPhoto)
+	router.PATCH(baseURL+"/users/:id/profile", wrapper.UpdateUserProfile)
+	router.PATCH(baseURL+"/users/:id/surname", wrapper.UpdateUserSurname)
+	router.PUT(baseURL+"/users/:id/tag", wrapper.AddUserTag)
+	router.GET(baseURL+"/users/:id/tags", wrapper.GetUserTags)
+	router.DELETE(baseURL+"/users/:id/tags/:tagId", wrapper.RemoveUserTag)
+	router.POST(baseURL+"/users:batchGet", wrapper.BatchGetUsers)
+
+}
+
+// Base64 encoded, gzipped, json marshaled Swagger object
+var swaggerSpec = []string{
+
+	"H4sIAAAAAAAC/+w8+3PbNpP/CoZ3M9fO0ZLy6PU+fz85bR5Ok9TnONObSTweiFiJqEmAAUDbmoz/95sF",
+	"wIdI0KJsy3nc90sbmcBisW/sLvAlSmReSAHC6Gj/S1RQRXMwoOyvpFRaKvwXA50oXhguRbQfCbgyZ+4j",
+	"WSiZE5MCKRRccFlqUtAlRHHEceTnEtQqiiNBc4j2K4BxpJMUcoqQzarAL9ooLpbR9XUccQZ5IQ2IZPUH",
+	"rPqr/wErYiTJ6TnYhRV8LkEbUs8zE3JAFBRADbnkJrWjNM2BnMOKUMHIXLIVUWBKJbT7aqQCRhToQgoN",
+	"btphDXDvGIqMroDtE6NK+CehhPHFAhQI44BxTRT8DYkB5iY/ffx4Qv5KeeaQXHClTY2qKoWOPYqaLMGQ",
+	"p7N/OKzdAJNSQwRcgCLIoAwQ7kIBaMKNdvtYGFCEEp1KZUgGVMOEPJOMgyYSJ+KyiRQLvixxbxnPuSFU",
+	"QRfRR08mn0TFsBQoA9Vw7LBhxh5yo826hVQ5NdF+VJacRXGAlXZNHBoSBvexDTCnVzwv82j/0WwWRzkX",
+	"/lcNmgsDS1AWtlwsNAwC91/XoFfwZkF4RSqNPGR9eTvCD+Tw94pEBTVps1A1LY6Qd1wBi/ZRRrYjlKHL",
+	"0NIndDm4sJtyt2VLDSq07gcNanBhfrdVr+OoUjNrZI6UnGeQ4z8TKVDb8J+0KDKeUMRnWrgR//m3RuS+",
+	"tNb6dwWLaD/6t2ljxabuq55WcO2K69t7rpRUhAty/OI38ut/z34l7SWI3wHO88BwrYO5LM2HglED+LNQ",
+	"sgBluNsExY9n+UpDtvCS/AbE0qRWlmch2jcU/Lg+/bQeLeeoqNF1HB2UjJtjSKRigcUTt6+eMY3xk1Rn",
+	"nI1gTBwlCqgBdkbN2nDc8Z7hOYTmMDCUZ7ovQq/f//mOgEgkA0b8KCIX1ip5hAPgRmJqqFqCOUPxHbe5",
+	"Dr3tkJo2cdQgtA54jSY9vsTR1d5S7vk/5pJBpidtTrUG7PG8kMo4H4tiES25Scv5JJH59BwUz/680Cjp",
+	"C57BmYYLnsAUzZMSNJs62HYbz6g4dk6iLwgKqFeREfLXk7EXHDJmVaMPeYHf+kx+h07VM7UOHYhUBPdY",
+	"fbBzCReaM+cL0WHGBCbLCVmCcO6mLwl2HyDQYH+sLFBl5GsvhaCi08D0HLTGOGT/ywZRcDuz6zWzQir4",
+	"0qEatJX/of1OMAgRhhtEssL97cGb51EcvXhu/9FDNihFCPRlRZv7FyLk25Apc1a+K0KbNMrOCpHtSPGc",
+	"qpV1okNL3lKFw8vV3mSdTV1TX9mkKO4ggxarP/29ofMMSE6TlAvYU0CZ/QNYV4JzvERb0yGkOVvIUrBh",
+	"k9lf4lWZU9GCfFVkVFgP6DSJayKTpFQKRBK0xVaUA6bYKvZeBheQkQuaceaAWtRx/9xArje505Z1aIwH",
+	"VYqunLZqQxGtPt19RGstgjsnOPr7vTAS9gTaUFMGNvPq5OSIuI+W7FE/mIsjw00WYqINldN1OusyRwEN",
+	"uplVEYDy4fjQq/lixcVybVN2xibRrQZZJOudxk7yQkJ9DJRxAVr3VSdJITm3/6KMccSQZkdrIwLy1xFs",
+	"Y2040CQlDAoQDKP9mHyK5PmnCK057tAJuoErEwUwbLhVWT15HsVRKegF5RnSOTrdRJaGDm5PIUq8L5W4",
+	"wXZp93l781VNDK15QpcHLBB2JdTAUqpVZ7VfZsGAZbl5WFdM6DKID/qGzRFo0Enhx732lwCqcyrOmkBi",
+	"Hcxf6coHcIkshSGXVJM5FQLYACRRh5NdQCCGIY0LPedcmfSsEoTgfu0QYoeMhboeBA9gjUbeouxHj4a+",
+	"rIOIm2yt9/zXcZRyxiDAiCPn4zHt4IaQ+YpQgi5eUSNbEdVcygyoaILrDqEE/1xCZc84KDz91HuM4k1e",
+	"OY7+LsXyLKPanFFjIC820g3HEj8WmP376xLtKGgzmpB2VQW6zMwg9y3UApRGo8jNyq5A/KQAzMp0BIG5",
+	"BI4dEphqswAulhnjTBGmjYhCvrRwEdOZhRlyP2+qyLp0qPkJxE0I+dLGLAb3ZjkytDVDl9tt7IQu+9sK",
+	"HcD8ihV2tzhsfdA7CpAR8DNqkrRvaHOuNZJmKNIBRjjTNrcmS0MoueCaY6Dhl20HXJsPuh3ZQI5vx433",
+	"PrzZxBEHOa63N+R5LFVegglF8euYbdxcTq8O3eAq31f93Cg9ehC/36wM3Z9/3HSS3t6mh8zwIWtyI84h",
+	"cu8fS5MSDQoldYw1Hm/DNgRI21mN7aKtkPYP8fOILgPcrIVstB6EtKlVx+jv8jf794otONRWNmJC5xqE",
+	"IdIxyFLBlzw27NqiOrjPytrf4mgcR6XKgrH+3TJkTRoM4Y83yUfeEe3GLvvY575Ssd1AcjeBXDBKuodQ",
+	"509RJ+Ae/Vcg4lkVoH1m4vDdyeubTMY2BmGzvo8VlTVm7kpk3gNVSfqKh3KmVJyHfHkGF1QkEBMpspUt",
+	"wlFl8wXo2LkgUkBdLmyoKsp87hiuBS8KMKEExts3e6ATWtiSHl3m1posqjzCgvtFSI6uFjS5VLTAwVyQ",
+	"T+Vs9iSZ2/+B+zGtfgVPc6XeLKXOPAbigSh25Gl2M94GNCTfGVN9ZNO33MisxNTExBPNAoBpW/tNqGL9",
+	"rN9D2ICRtryj74P62vuw04PD1zwX2KW2EL06o7cbwTtxyZzbZ4PGOvXRzjuOLmhWwtYppo6Xd0DGExoJ",
+	"cf9ERrmDpFTcrN6j+Hi3XvA/YHVQmrQu+Xd7Ff537+Do0HcpVFJmZ1knD1SBqua7Xy8qqr7+66RqFcBZ",
+	"7msDJTWmcIhxsQho1sHRoTUyORV0iSlhm+fwG9V1qtfX1Q+ODpHWoLSb/WjyaDJDFGUBghY82o+eTGaT",
+	"WRRbMtrdTynLuZhSLC7i76XzLCiANpmPRfzoAMe84dq0apDW0rWaej72A4hsRZQbS2zs5EoN3v6Heisa",
+	"gdmi1yBsHxrUpr5jY8RI1zhyfdppJHg8m93QRNBvHhhluFqkDBivXjbdDq/JaY8F19aOelcVIXsIXRsl",
+	"F8Ry13qnOoPni+S6Mn/7Hx1/o1ME6OVBWyc7rbMCy1DAcVxqzakD/1wsM65TcunYrcAHGQzzhyYFrohG",
+	"skzISQrE8p3oskC11uQS5sStSPRKGHq1Tz5Fn0tpgJEiVVTDpygme3CVZCUDFhOpJuTYOjK3llQMlFsL",
+	"44p/2tMjwuXLlORSAfY9CYL7je1/W39sh/UT8solPW0vl80a19pmF+KiQkFL19/l0Oaa1AUJYqSju7Yh",
+	"nuuBCiiUC2Q++PRIR5dC6vH5xvaYln1+7LMedST946jNegQ4QnHeohhWplPHZA7auHwFzn46mw2tWO+l",
+	"afjB8U+2GL+moC/KLNvDMlclM7aXzgpqvCaEVvisat6koV3VHLDYA+L13fJ+DMvtnr2NvBPLnE3Nskp2",
+	"nPajMKVdM7GZV9MvnF07I5qBgQGe/W4/+vTzdkzzPXcjmNZphA1w7+lA657DnRFdJglovSizbOWI/PS2",
+	"RHY7JlSsXHTzk/NZaDp/DlA1vkHgX4K5E+XuKsUjzsJBYa1bRu5GyZdgGjLOV67Psk+/oio/BCjo0iVf",
+	"Q/xsieOZZKt7pfh6Fuj6+rrrQa/HiL6HQkoLJiT923qRW/PY7aRmsz1rO+Q26E3IGk3nVGy0SB/EnIpv",
+	"0SA9o4JkfGGA3ZGmb/gCy2lzKjYbn0LqIevzjIofQ3FanaC31ZgDX2zyzvFu/Hlmzxh1Aes2gp5yBiMk",
+	"HYd9i6JeGSBf8b0jPd/iBRdaxTDFOmxCl5SLbWX/1TdOOLfXO9INN0lok/1VMifSpKBIVeMeJYuupWP6",
+	"xV/w2BwTHkMuL6Bd/9oVjT1Ku2MHwifK7ueuVuGFVAnsOVjIFU+aUSzAQdMv9qLLNuR3acldEd+iszPS",
+	"442fnRDeWKKEyJ4CzUy6GjykvvLfw1F3p5vUdSpgoqUsonYqN9r/eLqmpRYqsb2eLcQ8AI9apYP+SBZE",
+	"7yWYAX0LCbVLaVdtC9vfbuomaLpMfTL7NVRNZFzhssZlo+y2yIfjN/VVBjv3jXRxwHoI0F3x+lZiMcAF",
+	"PIs4bOarDm0qjli6ac8QBZSthjON4BKF7kZl1Vd85RL8TW8xB01+YtTQOdUQk2M6n3Pz9n9+7uXfju1q",
+	"OzztNY3VoVxu1sGZKmjSh6idv8yePBAq7oKnsVXnGid767TdYn0Tp2v4G1Tu5lzyO7i0zfxVstVm6GJM",
+	"4nBB6yulVBBZUOwrdc01o/O1rthuYfg/nbmQICaXKU9S4hXTiZgPMFUraa5kBhNy6GtKVeOUJgKAIVpY",
+	"qTkHv0RVCdX7KNZEJ7KAUBr4hgRdKP9b32kax/iqPnwdd4n91l1YJXSJFCJ4jiUroErHth+hdHeCZU5a",
+	"xetwxYYu4Sy34WLosu0v7cu2wcuxPczo1X1iRq/uDbNgB8x6A0wIjXbN/cbb6SF/bcum9X3uzmU4rAtq",
+	"uABFsypbjDd77CUj51xC+PiRgczrptpy01T5eNYvtn8Jpd2dElSqu6qaBBAH8hODBS0z8zPqmRuRZX5E",
+	"Hg0jf5a7uznNDqqLIVTgUJplwRshwfKkw893BxNqEBd3/d2WKn1/RgiVuqMYR4cLlje0eIxCZw4LV6Qa",
+	"h4kbfitUQmDXzWRIdusLACNCVmeuH7CgsDEx6CqoG2sH2yb2Hu24nNQ44fXOhY+nSNt2L8LH0+vTfjFD",
+	"MLLgmQmcWe3O1875nU7inu9zktc43MpFV77QvjWRUt3yh36K84jkwP6V5HTlm+HcV7rerYazOavfA+GK",
+	"yEtBjDwHEXKqrk/7VsmIB0xMOyzH5dcePUwRorI7d89u71IJcPw/HkxpfqskUsBl1bnSVZg6uN1Y4ftu",
+	"i3tPHqq8URUDh2gdDx7V7RWSlX245P9T8e+G43ewDBgW2qntP/D9da44yKBQkFBTmaWumW0qhfb9ku+/",
+	"6tF+huXWZQ+EQTTYDqt7LBc+uWO50JcKaRu9EULBWN3xG879fygySdmus9JbCUBeZoYXVJkpRr97mA1a",
+	"l4H17tqBlubXR89f4mHg6N1LwnN3CaeOpudcBO/1d3pgHej+tZyHd/n+Rmjf/tgPpLRc/NqCijhUeXzM",
+	"Z66Xt4fyhi1xrRrKb2HC3rnM5HduwVqPr9zWgCGIb89udRLHA+aquSp9U4zgpedrRQl3vcXd12F7rpOL",
+	"Wl/c/nYRTDTQR/JiXI3zBy1vPrD63CnsPl6rorqj9qD9HWJ595LQQJ+ZU+32603fv+UNvEV1+16z1tWp",
+	"r2SK76NDbegm2CYhctK2SXxavX3/alTcZaPiVxObkVandZHwFnGff/Xp+xeh9eerbis+Hsq3FwB2bmwO",
+	"i4N/Dqsog41qbPdNPA/Eb/902Fc4S9rLtv0oFMuVlH3lQ+QBY64h6cbz47DobDw8nLjq6Td+dAjfh950",
+	"cLD739GxoXOb6QYOjOnK++Ea8r7LowKq2ZYHhf15+42nYJXx2L+dn6w/taCrZ4vsTc+qkUC1H8aakMOb",
+	"XsciP/kHqOKq8Vkq3zL0s20VyriDJIgfeO/tPtULV1XLz65iyWqdce5hdv9rh6zNC3y1tmGnvZLs6IzM",
+	"+9GK7Wj7quactgi7x10s20OK0l00sASoi4E2VCVZ6QoPbpB/Xcm9MqD3p1Na8Alc0bzIwL6ccPHImiqP",
+	"RbAkWClPLci66RBxWPdbWdpJZctnJi8F/mimHlVJql7bkGu4808TY8+QxyAO3q9eu9jeQs11IffBV13E",
+	"IFghuTCtKf5TdH16/X8DAIfZ/Ea8ZAAA",
+}
+
+// GetSwagger returns the content of the embedded swagger specification file
+// or error if failed to decode
+func decodeSpec() ([]byte, error) {
+	zipped, err := base64.StdEncoding.DecodeString(strings.Join(swaggerSpec, ""))
+	if err != nil {
+		return nil, fmt.Errorf("error base64 decoding spec: %w", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(zipped))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %w", err)
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(zr)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+var rawSpec = decodeSpecCached()
+
+// a naive cached of a decoded swagger spec
+func decodeSpecCached() func() ([]byte, error) {
+	data, err := decodeSpec()
+	return func() ([]byte, error) {
+		return data, err
+	}
+}
+
+// Constructs a synthetic filesystem for resolving external references when loading openapi specifications.
+func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
+	res := make(map[string]func() ([]byte, error))
+	if len(pathToFile) > 0 {
+		res[pathToFile] = rawSpec
+	}
+
+	return res
+}
+
+// GetSwagger returns the Swagger specification corresponding to the generated code
+// in this file. The external references of Swagger specification are resolved.
+// The logic of resolving external references is tightly connected to "import-mapping" feature.
+// Externally referenced files must be embedded in the corresponding golang packages.
+// Urls can be supported but this task was out of the scope.
+func GetSwagger() (swagger *openapi3.T, err error) {
+	resolvePath := PathToRawSpec("")
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(loader *openapi3.Loader, url *url.URL) ([]byte, error) {
+		pathToFile := url.String()
+		pathToFile = path.Clean(pathToFile)
+		getSpec, ok := resolvePath[pathToFile]
+		if !ok {
+			err1 := fmt.Errorf("path not found: %s", pathToFile)
+			return nil, err1
+		}
+		return getSpec()
+	}
+	var specData []byte
+	specData, err = rawSpec()
+	if err != nil {
+		return
+	}
+	swagger, err = loader.LoadFromData(specData)
+	if err != nil {
+		return
+	}
+	return
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/kerilOvs/profile_sevice/internal/config"
+)
+
+// Скоупы сервисных ключей
+const (
+	ScopeUsersCreate = "users:create"
+	ScopeUsersRead   = "users:read"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+type apiKey struct {
+	name   string
+	hash   []byte
+	scopes []string
+}
+
+// APIKeys проверяет ключи внутренних сервисов. В конфиге хранятся только
+// sha256 хеши ключей (hex), сами ключи знают только вызывающие сервисы.
+type APIKeys struct {
+	keys []apiKey
+}
+
+func NewAPIKeys(cfg []config.APIKeyConfig) (*APIKeys, error) {
+	keys := make([]apiKey, 0, len(cfg))
+	for _, k := range cfg {
+		hash, err := hex.DecodeString(k.KeyHash)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("invalid sha256 hash for api key %q", k.Name)
+		}
+		keys = append(keys, apiKey{
+			name:   k.Name,
+			hash:   hash,
+			scopes: k.Scopes,
+		})
+	}
+
+	return &APIKeys{keys: keys}, nil
+}
+
+// Authenticate возвращает Principal сервиса, которому принадлежит ключ.
+func (a *APIKeys) Authenticate(key string) (*Principal, error) {
+	if key == "" {
+		return nil, ErrInvalidAPIKey
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	// Проходим по всем ключам, чтобы время ответа не зависело от позиции ключа
+	var found *apiKey
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(sum[:], a.keys[i].hash) == 1 {
+			found = &a.keys[i]
+		}
+	}
+	if found == nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	return &Principal{
+		Service: found.name,
+		Scopes:  found.scopes,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kerilOvs/profile_sevice/internal/config"
+)
+
+func keyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestAPIKeysAuthenticate(t *testing.T) {
+	keys, err := NewAPIKeys([]config.APIKeyConfig{
+		{Name: "auth", KeyHash: keyHash("auth-key"), Scopes: []string{ScopeUsersCreate}},
+		// Хеш в конфиге может быть записан заглавными буквами
+		{Name: "matching", KeyHash: strings.ToUpper(keyHash("matching-key")), Scopes: []string{ScopeUsersRead}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key     string
+		service string
+		scopes  []string
+	}{
+		{"auth-key", "auth", []string{ScopeUsersCreate}},
+		{"matching-key", "matching", []string{ScopeUsersRead}},
+	}
+	for _, tt := range tests {
+		principal, err := keys.Authenticate(tt.key)
+		if err != nil {
+			t.Fatalf("Authenticate(%q): %v", tt.key, err)
+		}
+		if principal.Service != tt.service || !slices.Equal(principal.Scopes, tt.scopes) || !principal.IsService() {
+			t.Fatalf("Authenticate(%q) = %+v, want service %q with %v", tt.key, principal, tt.service, tt.scopes)
+		}
+	}
+
+	// Сам хеш ключом не является, как и ключ с лишними символами
+	for _, key := range []string{"", "unknown", keyHash("auth-key"), "auth-key ", "AUTH-KEY"} {
+		if principal, err := keys.Authenticate(key); !errors.Is(err, ErrInvalidAPIKey) || principal != nil {
+			t.Fatalf("Authenticate(%q) = %+v, %v, want ErrInvalidAPIKey", key, principal, err)
+		}
+	}
+}
+
+func TestNewAPIKeysRejectsInvalidHash(t *testing.T) {
+	for _, hash := range []string{"", "not-hex", keyHash("key")[:32], keyHash("key") + "00"} {
+		_, err := NewAPIKeys([]config.APIKeyConfig{{Name: "broken", KeyHash: hash}})
+		if err == nil || !strings.Contains(err.Error(), `"broken"`) {
+			t.Fatalf("NewAPIKeys(%q) error = %v, want an error naming the key", hash, err)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultJWKSRefresh = 15 * time.Minute
+	// Не дергаем JWKS чаще этого интервала, даже если приходят токены с неизвестным kid
+	// или сервер JWKS отвечает ошибкой
+	defaultJWKSMinRefresh = 30 * time.Second
+	jwksTimeout           = 5 * time.Second
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// jwksCache хранит ключи из JWKS и перечитывает их по истечении refresh
+// или при появлении токена с неизвестным kid (ротация ключей).
+type jwksCache struct {
+	url        string
+	refresh    time.Duration
+	minRefresh time.Duration
+	client     *http.Client
+
+	// Одновременные промахи по kid ждут один общий запрос к JWKS
+	group singleflight.Group
+
+	mu          sync.RWMutex
+	keys        map[string]any
+	fetchedAt   time.Time
+	attemptedAt time.Time // последняя попытка, в том числе неудачная
+}
+
+func newJWKSCache(url string, refresh, minRefresh time.Duration, client *http.Client) *jwksCache {
+	if refresh <= 0 {
+		refresh = defaultJWKSRefresh
+	}
+	if minRefresh <= 0 {
+		minRefresh = defaultJWKSMinRefresh
+	}
+	if client == nil {
+		client = &http.Client{Timeout: jwksTimeout}
+	}
+
+	return &jwksCache{
+		url:        url,
+		refresh:    refresh,
+		minRefresh: minRefresh,
+		client:     client,
+		keys:       map[string]any{},
+	}
+}
+
+func (c *jwksCache) key(ctx context.Context, kid string) (any, error) {
+	c.mu.RLock()
+	key, ok := c.keys[kid]
+	fresh := time.Since(c.fetchedAt) < c.refresh
+	c.mu.RUnlock()
+
+	if ok && fresh {
+		return key, nil
+	}
+
+	if err := c.refetch(ctx); err != nil {
+		// Если ключ уже известен, продолжаем работать со старым набором
+		if ok {
+			return key, nil
+		}
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	key, ok = c.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: kid %q", ErrUnknownKey, kid)
+	}
+
+	return key, nil
+}
+
+// refetch перечитывает JWKS не чаще minRefresh. Одновременные вызовы выполняют один запрос.
+func (c *jwksCache) refetch(ctx context.Context) error {
+	_, err, _ := c.group.Do(c.url, func() (any, error) {
+		c.mu.Lock()
+		if time.Since(c.attemptedAt) < c.minRefresh {
+			c.mu.Unlock()
+			return nil, nil
+		}
+		c.attemptedAt = time.Now()
+		c.mu.Unlock()
+
+		// Результат общий для всех ждущих, поэтому отмена запроса первого из них его не прерывает
+		return nil, c.fetch(context.WithoutCancel(ctx))
+	})
+	return err
+}
+
+func (c *jwksCache) fetch(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, jwksTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build jwks request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch jwks: unexpected status %d", resp.StatusCode)
+	}
+
+	var set jwkSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("failed to decode jwks: %w", err)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			// Неподдерживаемые ключи пропускаем, остальные остаются рабочими
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	c.mu.Lock()
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
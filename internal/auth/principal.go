@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+
+	DefaultRolesClaim = "roles"
+)
+
+// Principal - аутентифицированный субъект запроса: пользователь (JWT)
+// или внутренний сервис (API ключ). У сервиса UserID пустой.
+type Principal struct {
+	UserID  uuid.UUID
+	Service string
+	Roles   []string
+	Scopes  []string
+}
+
+func (p *Principal) IsService() bool {
+	return p.Service != ""
+}
+
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if slices.Contains(p.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+// PrincipalFromClaims собирает Principal из проверенных claims.
+// Роли читаются из claim rolesClaim, скоупы - из "scope" или "scp".
+func PrincipalFromClaims(claims jwt.MapClaims, rolesClaim string) (*Principal, error) {
+	sub, err := claims.GetSubject()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subject from jwt: %w", err)
+	}
+
+	id, err := uuid.Parse(sub)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject in jwt: %w", err)
+	}
+
+	scopes := stringList(claims["scope"])
+	if len(scopes) == 0 {
+		scopes = stringList(claims["scp"])
+	}
+
+	return &Principal{
+		UserID: id,
+		Roles:  stringList(claims[rolesClaim]),
+		Scopes: scopes,
+	}, nil
+}
+
+// stringList принимает как массив строк, так и строку через пробел.
+func stringList(v any) []string {
+	switch val := v.(type) {
+	case string:
+		return strings.Fields(val)
+	case []any:
+		res := make([]string, 0, len(val))
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kerilOvs/profile_sevice/internal/config"
+)
+
+var (
+	ErrNoToken      = errors.New("no authorization header provided")
+	ErrInvalidToken = errors.New("invalid jwt")
+	ErrNoVerifier   = errors.New("no jwt verification key configured")
+)
+
+// Verifier проверяет подпись и стандартные claims (exp, nbf, iss, aud) JWT.
+// Ключи берутся из JWKS, из статического PEM или из HMAC секрета.
+type Verifier struct {
+	jwks      *jwksCache
+	staticKey any
+	hmacKey   []byte
+	parser    *jwt.Parser
+
+	rolesClaim string
+}
+
+func NewVerifier(cfg config.AuthConfig) (*Verifier, error) {
+	return NewVerifierWithClient(cfg, nil)
+}
+
+// NewVerifierWithClient позволяет подменить http клиент для JWKS (например, на заглушку).
+func NewVerifierWithClient(cfg config.AuthConfig, client *http.Client) (*Verifier, error) {
+	v := &Verifier{rolesClaim: cfg.RolesClaim}
+	if v.rolesClaim == "" {
+		v.rolesClaim = DefaultRolesClaim
+	}
+	var methods []string
+
+	if cfg.JWKSURL != "" {
+		v.jwks = newJWKSCache(cfg.JWKSURL, cfg.JWKSRefresh, cfg.JWKSMinRefresh, client)
+		methods = append(methods, asymmetricMethods...)
+	}
+
+	pemData, err := readPEM(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if pemData != nil {
+		key, err := parsePublicKeyPEM(pemData)
+		if err != nil {
+			return nil, err
+		}
+		v.staticKey = key
+		if v.jwks == nil {
+			methods = append(methods, asymmetricMethods...)
+		}
+	}
+
+	if cfg.HMACSecret != "" {
+		v.hmacKey = []byte(cfg.HMACSecret)
+		methods = append(methods, "HS256", "HS384", "HS512")
+	}
+
+	if len(methods) == 0 {
+		return nil, ErrNoVerifier
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(opts...)
+
+	return v, nil
+}
+
+var asymmetricMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// Verify проверяет токен и возвращает его claims.
+func (v *Verifier) Verify(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		return v.keyFor(ctx, token)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return claims, nil
+}
+
+// Authenticate достает из запроса Bearer токен, проверяет его и возвращает Principal.
+func (v *Verifier) Authenticate(r *http.Request) (*Principal, error) {
+	tokenString, err := BearerToken(r)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := v.Verify(r.Context(), tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	return PrincipalFromClaims(claims, v.rolesClaim)
+}
+
+func BearerToken(r *http.Request) (string, error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return "", ErrNoToken
+	}
+
+	tokenString, ok := strings.CutPrefix(authHeader, "Bearer ")
+	if !ok || tokenString == "" {
+		return "", ErrNoToken
+	}
+
+	return tokenString, nil
+}
+
+func (v *Verifier) keyFor(ctx context.Context, token *jwt.Token) (any, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if v.hmacKey == nil {
+			return nil, ErrUnknownKey
+		}
+		return v.hmacKey, nil
+	}
+
+	if kid, _ := token.Header["kid"].(string); kid != "" && v.jwks != nil {
+		return v.jwks.key(ctx, kid)
+	}
+
+	if v.staticKey != nil {
+		return v.staticKey, nil
+	}
+
+	return nil, ErrUnknownKey
+}
+
+func readPEM(cfg config.AuthConfig) ([]byte, error) {
+	if cfg.PublicKeyPEM != "" {
+		return []byte(cfg.PublicKeyPEM), nil
+	}
+	if cfg.PublicKeyFile != "" {
+		data, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read public key: %w", err)
+		}
+		return data, nil
+	}
+	return nil, nil
+}
+
+func parsePublicKeyPEM(data []byte) (any, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseECPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("failed to parse public key pem")
+}
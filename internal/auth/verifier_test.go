@@ -0,0 +1,330 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kerilOvs/profile_sevice/internal/config"
+)
+
+const (
+	testIssuer   = "https://auth.test"
+	testAudience = "profile-service"
+)
+
+// jwksStub - сервер JWKS с подменяемым набором ключей и счетчиком запросов.
+type jwksStub struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	keys     map[string]*rsa.PrivateKey
+	status   int
+	delay    time.Duration
+	requests atomic.Int32
+}
+
+func newJWKSStub(t *testing.T) *jwksStub {
+	t.Helper()
+
+	stub := &jwksStub{keys: map[string]*rsa.PrivateKey{}, status: http.StatusOK}
+	stub.Server = httptest.NewServer(http.HandlerFunc(stub.serve))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+func (s *jwksStub) serve(w http.ResponseWriter, _ *http.Request) {
+	s.requests.Add(1)
+
+	s.mu.Lock()
+	status, delay := s.status, s.delay
+	set := jwkSet{}
+	for kid, key := range s.keys {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	s.mu.Unlock()
+
+	time.Sleep(delay)
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(set)
+}
+
+// rotate заменяет набор ключей на переданный.
+func (s *jwksStub) rotate(keys map[string]*rsa.PrivateKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func (s *jwksStub) setStatus(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func publicKeyPEM(t *testing.T, key *rsa.PrivateKey) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"sub": "7d7cf2a4-6c3b-4f43-9a43-3a1e5c6b2f10",
+		"iss": testIssuer,
+		"aud": testAudience,
+		"iat": now.Unix(),
+		"nbf": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func newTestVerifier(t *testing.T, cfg config.AuthConfig) *Verifier {
+	t.Helper()
+
+	cfg.Issuer = testIssuer
+	cfg.Audience = testAudience
+	v, err := NewVerifier(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestVerifierJWKSRotation(t *testing.T) {
+	ctx := context.Background()
+	stub := newJWKSStub(t)
+	oldKey, newKey := newRSAKey(t), newRSAKey(t)
+	stub.rotate(map[string]*rsa.PrivateKey{"old": oldKey})
+
+	v := newTestVerifier(t, config.AuthConfig{JWKSURL: stub.URL, JWKSMinRefresh: time.Nanosecond})
+
+	if _, err := v.Verify(ctx, signRS256(t, oldKey, "old", validClaims())); err != nil {
+		t.Fatalf("token with current key: %v", err)
+	}
+
+	// Новый kid - повод перечитать JWKS, старый ключ после ротации больше не принимается
+	stub.rotate(map[string]*rsa.PrivateKey{"new": newKey})
+	if _, err := v.Verify(ctx, signRS256(t, newKey, "new", validClaims())); err != nil {
+		t.Fatalf("token with rotated key: %v", err)
+	}
+	if _, err := v.Verify(ctx, signRS256(t, oldKey, "old", validClaims())); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("token with retired key: err = %v, want ErrUnknownKey", err)
+	}
+
+	// Ключ с известным kid, но чужой подписью
+	if _, err := v.Verify(ctx, signRS256(t, oldKey, "new", validClaims())); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("token signed by another key: err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestVerifierUnknownKidIsRateLimited(t *testing.T) {
+	ctx := context.Background()
+	stub := newJWKSStub(t)
+	key := newRSAKey(t)
+	stub.rotate(map[string]*rsa.PrivateKey{"current": key})
+
+	v := newTestVerifier(t, config.AuthConfig{JWKSURL: stub.URL, JWKSMinRefresh: time.Hour})
+
+	if _, err := v.Verify(ctx, signRS256(t, key, "current", validClaims())); err != nil {
+		t.Fatal(err)
+	}
+	for range 5 {
+		if _, err := v.Verify(ctx, signRS256(t, key, "unknown", validClaims())); !errors.Is(err, ErrUnknownKey) {
+			t.Fatalf("err = %v, want ErrUnknownKey", err)
+		}
+	}
+	if got := stub.requests.Load(); got != 1 {
+		t.Fatalf("jwks requests = %d, want 1", got)
+	}
+}
+
+func TestVerifierConcurrentMissesShareOneFetch(t *testing.T) {
+	stub := newJWKSStub(t)
+	key := newRSAKey(t)
+	stub.rotate(map[string]*rsa.PrivateKey{"current": key})
+	stub.delay = 50 * time.Millisecond
+
+	v := newTestVerifier(t, config.AuthConfig{JWKSURL: stub.URL, JWKSMinRefresh: time.Hour})
+	token := signRS256(t, key, "current", validClaims())
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := v.Verify(context.Background(), token)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := stub.requests.Load(); got != 1 {
+		t.Fatalf("jwks requests = %d, want 1", got)
+	}
+}
+
+func TestVerifierJWKSFailure(t *testing.T) {
+	ctx := context.Background()
+	stub := newJWKSStub(t)
+	key := newRSAKey(t)
+	stub.rotate(map[string]*rsa.PrivateKey{"current": key})
+
+	v := newTestVerifier(t, config.AuthConfig{JWKSURL: stub.URL, JWKSRefresh: time.Nanosecond, JWKSMinRefresh: time.Hour})
+	token := signRS256(t, key, "current", validClaims())
+	if _, err := v.Verify(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+
+	// Набор устарел, но сервер недоступен: работаем со старыми ключами и не долбим сервер
+	stub.setStatus(http.StatusInternalServerError)
+	v.jwks.attemptedAt = time.Time{}
+	for range 3 {
+		if _, err := v.Verify(ctx, token); err != nil {
+			t.Fatalf("known key during jwks outage: %v", err)
+		}
+	}
+	if got := stub.requests.Load(); got != 2 {
+		t.Fatalf("jwks requests = %d, want 2", got)
+	}
+}
+
+func TestVerifierRejectsClaims(t *testing.T) {
+	ctx := context.Background()
+	stub := newJWKSStub(t)
+	key := newRSAKey(t)
+	stub.rotate(map[string]*rsa.PrivateKey{"current": key})
+
+	v := newTestVerifier(t, config.AuthConfig{JWKSURL: stub.URL})
+
+	tests := []struct {
+		name   string
+		change func(jwt.MapClaims)
+		want   error
+	}{
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }, jwt.ErrTokenExpired},
+		{"no exp", func(c jwt.MapClaims) { delete(c, "exp") }, jwt.ErrTokenRequiredClaimMissing},
+		{"not yet valid", func(c jwt.MapClaims) { c["nbf"] = time.Now().Add(time.Hour).Unix() }, jwt.ErrTokenNotValidYet},
+		{"wrong issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.test" }, jwt.ErrTokenInvalidIssuer},
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "another-service" }, jwt.ErrTokenInvalidAudience},
+		{"no audience", func(c jwt.MapClaims) { delete(c, "aud") }, jwt.ErrTokenRequiredClaimMissing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			tt.change(claims)
+
+			_, err := v.Verify(ctx, signRS256(t, key, "current", claims))
+			if !errors.Is(err, ErrInvalidToken) || !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := v.Verify(ctx, signRS256(t, key, "current", validClaims())); err != nil {
+		t.Fatalf("valid token: %v", err)
+	}
+}
+
+func TestVerifierRejectsAlgorithmConfusion(t *testing.T) {
+	ctx := context.Background()
+	stub := newJWKSStub(t)
+	key := newRSAKey(t)
+	stub.rotate(map[string]*rsa.PrivateKey{"current": key})
+	publicPEM := publicKeyPEM(t, key)
+
+	configs := map[string]config.AuthConfig{
+		"jwks":            {JWKSURL: stub.URL},
+		"pem":             {PublicKeyPEM: string(publicPEM)},
+		"pem and hmac":    {PublicKeyPEM: string(publicPEM), HMACSecret: "shared-secret"},
+		"jwks, pem, hmac": {JWKSURL: stub.URL, PublicKeyPEM: string(publicPEM), HMACSecret: "shared-secret"},
+	}
+	for name, cfg := range configs {
+		t.Run(name, func(t *testing.T) {
+			v := newTestVerifier(t, cfg)
+
+			// HS256, где секретом выступает публичный RSA ключ
+			for _, secret := range [][]byte{publicPEM, x509.MarshalPKCS1PublicKey(&key.PublicKey)} {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims())
+				token.Header["kid"] = "current"
+				signed, err := token.SignedString(secret)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := v.Verify(ctx, signed); !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("HS256 signed with the RSA public key: err = %v, want ErrInvalidToken", err)
+				}
+			}
+
+			unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := v.Verify(ctx, unsigned); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("alg none: err = %v, want ErrInvalidToken", err)
+			}
+
+			if _, err := v.Verify(ctx, signRS256(t, key, "current", validClaims())); err != nil {
+				t.Fatalf("RS256 token: %v", err)
+			}
+		})
+	}
+}
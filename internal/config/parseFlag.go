@@ -4,10 +4,11 @@ import "flag"
 
 var fileName string
 
+// Флаг только регистрируется, разбирает его main: flag.Parse в init ломает go test,
+// у тестового бинаря свои флаги (-test.*)
 func init() {
 
 	flag.StringVar(&fileName, "config", "configs/config.yaml", "Read file with configuration data")
-	flag.Parse()
 }
 
 // запомни еблан, путь указывается от корня, но корня не включая. типа cmd/service
@@ -1,11 +1,14 @@
 package config
 
 import (
+	"errors"
+	"io/fs"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/caarlos0/env/v11"
-	errors "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
 	"github.com/kerilOvs/profile_sevice/pkg/logger"
 
 	yaml "gopkg.in/yaml.v3"
@@ -17,10 +20,25 @@ type DBConfig struct {
 	User     string `yaml:"user" env:"DB_USER"`
 	Password string `yaml:"password" env:"DB_PASSWORD"`
 	Dbname   string `yaml:"dbname" env:"DB_NAME"`
+	// Применять миграции при старте. Если выключено, их применяет отдельный запуск `service migrate`
+	AutoMigrate bool `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+}
+
+// StorageConfig - где хранятся профили: "postgres" или "memory" (в памяти процесса,
+// данные теряются при перезапуске, для тестов и локальной разработки).
+type StorageConfig struct {
+	Backend string `yaml:"backend" env:"STORAGE_BACKEND"`
 }
 
 type ServerConfig struct {
-	Port int `yaml:"port" env:"SERVER_PORT"`
+	Port           int           `yaml:"port" env:"SERVER_PORT"`
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl" env:"SERVER_IDEMPOTENCY_TTL"`
+	// Сколько ключ держит запрос, который так и не завершился (например, процесс упал)
+	IdempotencyLease time.Duration `yaml:"idempotency_lease" env:"SERVER_IDEMPOTENCY_LEASE"`
+	// Максимальный размер тела запроса с Idempotency-Key в байтах, больше - 413
+	IdempotencyMaxBody int64 `yaml:"idempotency_max_body" env:"SERVER_IDEMPOTENCY_MAX_BODY"`
+	// Проверять ответы по openapi.yaml (для dev окружения, ответы буферизуются)
+	ValidateResponses bool `yaml:"validate_responses" env:"SERVER_VALIDATE_RESPONSES"`
 }
 
 type MinioConfig struct {
@@ -34,10 +52,92 @@ type MinioConfig struct {
 }
 
 type RabbitConfig struct {
-	Url            string `yaml:"url" env:"RABBIT_URL"`
-	QueuePhotoName string `yaml:"queue_photo_name" env:"RABBIT_PHOTO_NAME"`
-	QueueTagsName  string `yaml:"queue_tags_name" env:"RABBIT_TAGS_NAME"`
-	QueueAnketName string `yaml:"queue_anket_name" env:"RABBIT_ANKET_NAME"`
+	Url string `yaml:"url" env:"RABBIT_URL"`
+	// Topic exchange для событий профиля, routing key = тип события (profile.tags.updated, ...)
+	Exchange string `yaml:"exchange" env:"RABBIT_EXCHANGE"`
+	// Очереди сервиса подбора, привязываются к exchange при старте. Пустое имя - не объявлять
+	QueuePhotoName string          `yaml:"queue_photo_name" env:"RABBIT_PHOTO_NAME"`
+	QueueTagsName  string          `yaml:"queue_tags_name" env:"RABBIT_TAGS_NAME"`
+	QueueAnketName string          `yaml:"queue_anket_name" env:"RABBIT_ANKET_NAME"`
+	Durable        QueueDurability `yaml:"durable"`
+	// Задержка переподключения растет от ReconnectDelay до ReconnectMaxDelay
+	ReconnectDelay    time.Duration `yaml:"reconnect_delay" env:"RABBIT_RECONNECT_DELAY"`
+	ReconnectMaxDelay time.Duration `yaml:"reconnect_max_delay" env:"RABBIT_RECONNECT_MAX_DELAY"`
+	// События сервиса авторизации, по которым создаются и удаляются профили
+	Consumer ConsumerConfig `yaml:"consumer"`
+}
+
+// ConsumerConfig - подписка на события сервиса авторизации. Пустое имя очереди - consumer не запускается.
+type ConsumerConfig struct {
+	Exchange        string `yaml:"exchange" env:"RABBIT_CONSUMER_EXCHANGE"`
+	Queue           string `yaml:"queue" env:"RABBIT_CONSUMER_QUEUE"`
+	DeadLetterQueue string `yaml:"dead_letter_queue" env:"RABBIT_CONSUMER_DLQ"`   // по умолчанию <queue>.dlq
+	RetryQueue      string `yaml:"retry_queue" env:"RABBIT_CONSUMER_RETRY_QUEUE"` // по умолчанию <queue>.retry
+	Prefetch        int    `yaml:"prefetch" env:"RABBIT_CONSUMER_PREFETCH"`
+	// Через сколько вернуть в очередь сообщение, которое не удалось обработать из-за временной ошибки
+	RetryDelay time.Duration `yaml:"retry_delay" env:"RABBIT_CONSUMER_RETRY_DELAY"`
+	// После стольких неудачных попыток сообщение уходит в dead-letter очередь
+	MaxAttempts int           `yaml:"max_attempts" env:"RABBIT_CONSUMER_MAX_ATTEMPTS"`
+	StopTimeout time.Duration `yaml:"stop_timeout" env:"RABBIT_CONSUMER_STOP_TIMEOUT"`
+}
+
+// QueueDurability - durable флаг для каждой очереди, по умолчанию false, как объявлял
+// исходный сервис. Поменять его у уже существующей очереди нельзя: RabbitMQ ответит
+// PRECONDITION_FAILED и сервис не стартует. Очередь нужно удалить и дать сервису
+// создать ее заново или перейти на новое имя очереди.
+type QueueDurability struct {
+	Photo bool `yaml:"photo" env:"RABBIT_PHOTO_DURABLE"`
+	Tags  bool `yaml:"tags" env:"RABBIT_TAGS_DURABLE"`
+	Anket bool `yaml:"anket" env:"RABBIT_ANKET_DURABLE"`
+}
+
+// EventsConfig - куда relay отправляет события: "rabbit", "nats" (JetStream) или "memory"
+// (без брокера, события только пишутся в лог, для локальной разработки).
+type EventsConfig struct {
+	Publisher string `yaml:"publisher" env:"EVENTS_PUBLISHER"`
+	// Кроме profile.tags.updated.v2 слать теги строкой в profile.tags.updated, пока
+	// получатели не перешли на новый формат. Очередь тегов привязывается к тому формату,
+	// который сейчас основной
+	LegacyTags bool `yaml:"legacy_tags" env:"EVENTS_LEGACY_TAGS"`
+	// То же для анкеты: profile.anket.updated с датой ДД/ММ/ГГГГ и значениями по умолчанию
+	LegacyAnket bool `yaml:"legacy_anket" env:"EVENTS_LEGACY_ANKET"`
+}
+
+type NATSConfig struct {
+	Url    string `yaml:"url" env:"NATS_URL"`
+	Stream string `yaml:"stream" env:"NATS_STREAM"` // stream с subjects profile.>
+	// Запустить NATS внутри процесса вместо подключения к Url (локальная разработка, тесты)
+	Embedded bool   `yaml:"embedded" env:"NATS_EMBEDDED"`
+	StoreDir string `yaml:"store_dir" env:"NATS_STORE_DIR"` // каталог JetStream встроенного сервера, пустой - временный
+}
+
+type OutboxConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL"`
+	BatchSize    int           `yaml:"batch_size" env:"OUTBOX_BATCH_SIZE"`
+	MaxBackoff   time.Duration `yaml:"max_backoff" env:"OUTBOX_MAX_BACKOFF"`
+	Retention    time.Duration `yaml:"retention" env:"OUTBOX_RETENTION"` // сколько хранить отправленные сообщения
+}
+
+type AuthConfig struct {
+	JWKSURL     string        `yaml:"jwks_url" env:"AUTH_JWKS_URL"`
+	JWKSRefresh time.Duration `yaml:"jwks_refresh" env:"AUTH_JWKS_REFRESH"`
+	// Минимальный интервал между запросами JWKS при неизвестном kid или ошибке сервера
+	JWKSMinRefresh time.Duration `yaml:"jwks_min_refresh" env:"AUTH_JWKS_MIN_REFRESH"`
+	PublicKeyPEM   string        `yaml:"public_key_pem" env:"AUTH_PUBLIC_KEY_PEM"`
+	PublicKeyFile  string        `yaml:"public_key_file" env:"AUTH_PUBLIC_KEY_FILE"`
+	HMACSecret     string        `yaml:"hmac_secret" env:"AUTH_HMAC_SECRET"`
+	Issuer         string        `yaml:"issuer" env:"AUTH_ISSUER"`
+	Audience       string        `yaml:"audience" env:"AUTH_AUDIENCE"`
+	Leeway         time.Duration `yaml:"leeway" env:"AUTH_LEEWAY"`
+	RolesClaim     string        `yaml:"roles_claim" env:"AUTH_ROLES_CLAIM"`
+	// Ключи внутренних сервисов: AUTH_API_KEYS_0_NAME, AUTH_API_KEYS_0_HASH, AUTH_API_KEYS_0_SCOPES
+	APIKeys []APIKeyConfig `yaml:"api_keys" envPrefix:"AUTH_API_KEYS"`
+}
+
+type APIKeyConfig struct {
+	Name    string   `yaml:"name" env:"NAME"`
+	KeyHash string   `yaml:"hash" env:"HASH"` // sha256 от ключа в hex
+	Scopes  []string `yaml:"scopes" env:"SCOPES" envSeparator:","`
 }
 
 type LogConfig struct {
@@ -46,10 +146,15 @@ type LogConfig struct {
 }
 
 type Config struct {
-	Database DBConfig     `yaml:"database"`
-	Server   ServerConfig `yaml:"server"`
-	Minio    MinioConfig  `yaml:"minio"`
-	Rabbit   RabbitConfig `yaml:"rabbit"`
+	Database DBConfig      `yaml:"database"`
+	Storage  StorageConfig `yaml:"storage"`
+	Server   ServerConfig  `yaml:"server"`
+	Minio    MinioConfig   `yaml:"minio"`
+	Rabbit   RabbitConfig  `yaml:"rabbit"`
+	NATS     NATSConfig    `yaml:"nats"`
+	Events   EventsConfig  `yaml:"events"`
+	Outbox   OutboxConfig  `yaml:"outbox"`
+	Auth     AuthConfig    `yaml:"auth"`
 }
 
 func (c Config) LogValue() slog.Value {
@@ -60,9 +165,17 @@ func (c Config) LogValue() slog.Value {
 			slog.String("user", c.Database.User),
 			slog.Any("password", logger.Secret(c.Database.Password)),
 			slog.String("name", c.Database.User),
+			slog.Bool("auto_migrate", c.Database.AutoMigrate),
+		),
+		slog.Group("storage",
+			slog.String("backend", c.Storage.Backend),
 		),
 		slog.Group("server",
 			slog.Int("port", c.Server.Port),
+			slog.Duration("idempotency_ttl", c.Server.IdempotencyTTL),
+			slog.Duration("idempotency_lease", c.Server.IdempotencyLease),
+			slog.Int64("idempotency_max_body", c.Server.IdempotencyMaxBody),
+			slog.Bool("validate_responses", c.Server.ValidateResponses),
 		),
 		slog.Group("minio",
 			slog.String("endpoint", c.Minio.Endpoint),
@@ -73,34 +186,96 @@ func (c Config) LogValue() slog.Value {
 		),
 		slog.Group("rabbit",
 			slog.String("url", c.Rabbit.Url),
+			slog.String("exchange", c.Rabbit.Exchange),
 			slog.String("queue_photo_name", c.Rabbit.QueuePhotoName),
 			slog.String("queue_tags_name", c.Rabbit.QueueTagsName),
 			slog.String("queue_anket_name", c.Rabbit.QueueAnketName),
+			slog.Bool("photo_durable", c.Rabbit.Durable.Photo),
+			slog.Bool("tags_durable", c.Rabbit.Durable.Tags),
+			slog.Bool("anket_durable", c.Rabbit.Durable.Anket),
+			slog.Duration("reconnect_delay", c.Rabbit.ReconnectDelay),
+			slog.Duration("reconnect_max_delay", c.Rabbit.ReconnectMaxDelay),
+			slog.Group("consumer",
+				slog.String("exchange", c.Rabbit.Consumer.Exchange),
+				slog.String("queue", c.Rabbit.Consumer.Queue),
+				slog.String("dead_letter_queue", c.Rabbit.Consumer.DeadLetterQueue),
+				slog.String("retry_queue", c.Rabbit.Consumer.RetryQueue),
+				slog.Int("prefetch", c.Rabbit.Consumer.Prefetch),
+				slog.Duration("retry_delay", c.Rabbit.Consumer.RetryDelay),
+				slog.Int("max_attempts", c.Rabbit.Consumer.MaxAttempts),
+				slog.Duration("stop_timeout", c.Rabbit.Consumer.StopTimeout),
+			),
+		),
+		slog.Group("nats",
+			slog.String("url", c.NATS.Url),
+			slog.String("stream", c.NATS.Stream),
+			slog.Bool("embedded", c.NATS.Embedded),
+			slog.String("store_dir", c.NATS.StoreDir),
+		),
+		slog.Group("events",
+			slog.String("publisher", c.Events.Publisher),
+			slog.Bool("legacy_tags", c.Events.LegacyTags),
+			slog.Bool("legacy_anket", c.Events.LegacyAnket),
+		),
+		slog.Group("outbox",
+			slog.Duration("poll_interval", c.Outbox.PollInterval),
+			slog.Int("batch_size", c.Outbox.BatchSize),
+			slog.Duration("max_backoff", c.Outbox.MaxBackoff),
+			slog.Duration("retention", c.Outbox.Retention),
+		),
+		slog.Group("auth",
+			slog.String("jwks_url", c.Auth.JWKSURL),
+			slog.Duration("jwks_refresh", c.Auth.JWKSRefresh),
+			slog.Duration("jwks_min_refresh", c.Auth.JWKSMinRefresh),
+			slog.String("public_key_file", c.Auth.PublicKeyFile),
+			slog.Any("hmac_secret", logger.Secret(c.Auth.HMACSecret)),
+			slog.String("issuer", c.Auth.Issuer),
+			slog.String("audience", c.Auth.Audience),
+			slog.Duration("leeway", c.Auth.Leeway),
+			slog.String("roles_claim", c.Auth.RolesClaim),
+			slog.Int("api_keys", len(c.Auth.APIKeys)),
 		),
 	)
 }
 func ReadConfig() (Config, error) {
 
 	config := Config{
-		Database: DBConfig{Port: 5432},
-		Server:   ServerConfig{Port: 8080},
+		Database: DBConfig{Port: 5432, AutoMigrate: true},
+		Storage:  StorageConfig{Backend: "postgres"},
+		Server:   ServerConfig{Port: 8080, IdempotencyTTL: 24 * time.Hour, IdempotencyLease: time.Minute, IdempotencyMaxBody: 10 << 20},
+		Rabbit: RabbitConfig{
+			Exchange:          "profile.events",
+			ReconnectDelay:    500 * time.Millisecond,
+			ReconnectMaxDelay: 30 * time.Second,
+			Consumer: ConsumerConfig{
+				Exchange:    "auth.events",
+				Prefetch:    10,
+				RetryDelay:  5 * time.Second,
+				MaxAttempts: 10,
+				StopTimeout: 10 * time.Second,
+			},
+		},
+		NATS:   NATSConfig{Url: "nats://localhost:4222", Stream: "PROFILE_EVENTS"},
+		Events: EventsConfig{Publisher: "rabbit", LegacyTags: true, LegacyAnket: true},
+		Outbox: OutboxConfig{PollInterval: time.Second, BatchSize: 100, MaxBackoff: 5 * time.Minute, Retention: 7 * 24 * time.Hour},
+		Auth:   AuthConfig{RolesClaim: "roles"},
 	}
 
-	if fileName == "" {
+	// Сначала файл (если он есть), затем переменные окружения поверх него
+	if fileName != "" {
 		data, err := os.ReadFile(fileName)
-		if err != nil {
-			return config, errors.ErrorLocate(err)
-		}
-
-		err = yaml.Unmarshal(data, &config)
-		if err != nil {
-			return config, errors.ErrorLocate(err)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return config, errorsExt.ErrorLocate(err)
 		}
-	} else {
-		err := env.Parse(&config)
-		if err != nil {
-			return config, err
+		if err == nil {
+			if err := yaml.Unmarshal(data, &config); err != nil {
+				return config, errorsExt.ErrorLocate(err)
+			}
 		}
 	}
+
+	if err := env.Parse(&config); err != nil {
+		return config, err
+	}
 	return config, nil
 }
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func withConfigFile(t *testing.T, name string) {
+	t.Helper()
+
+	old := fileName
+	fileName = name
+	t.Cleanup(func() { fileName = old })
+}
+
+func TestReadConfigFileThenEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `
+database:
+  host: "db_from_file"
+  port: 6432
+rabbit:
+  queue_tags_name: "tags"
+  durable:
+    tags: true
+  consumer:
+    max_attempts: 3
+auth:
+  api_keys:
+    - name: "matching"
+      hash: "abc"
+      scopes: ["users:read"]
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	withConfigFile(t, path)
+	t.Setenv("DB_PORT", "7432")
+
+	cfg, err := ReadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.Host != "db_from_file" || cfg.Database.Port != 7432 {
+		t.Fatalf("database = %+v, want host from the file and port from env", cfg.Database)
+	}
+	if cfg.Rabbit.QueueTagsName != "tags" || !cfg.Rabbit.Durable.Tags || cfg.Rabbit.Durable.Photo {
+		t.Fatalf("rabbit = %+v", cfg.Rabbit)
+	}
+	if cfg.Rabbit.Consumer.MaxAttempts != 3 || cfg.Rabbit.Consumer.Prefetch != 10 {
+		t.Fatalf("consumer = %+v, want max_attempts from the file and the default prefetch", cfg.Rabbit.Consumer)
+	}
+	if len(cfg.Auth.APIKeys) != 1 || cfg.Auth.APIKeys[0].Name != "matching" {
+		t.Fatalf("api keys = %+v", cfg.Auth.APIKeys)
+	}
+}
+
+func TestReadConfigWithoutFile(t *testing.T) {
+	withConfigFile(t, filepath.Join(t.TempDir(), "missing.yaml"))
+	t.Setenv("SERVER_IDEMPOTENCY_TTL", "1h")
+
+	cfg, err := ReadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.IdempotencyTTL != time.Hour || cfg.Server.Port != 8080 {
+		t.Fatalf("server = %+v", cfg.Server)
+	}
+	// Исходный сервис объявлял очереди не durable, иначе redeclare упадет с PRECONDITION_FAILED
+	if cfg.Rabbit.Durable != (QueueDurability{}) {
+		t.Fatalf("durable = %+v, want all false by default", cfg.Rabbit.Durable)
+	}
+}
+
+func TestRepoConfigFileParses(t *testing.T) {
+	withConfigFile(t, filepath.Join("..", "..", "configs", "config.yaml"))
+
+	cfg, err := ReadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.Host != "db_pers" || cfg.Server.IdempotencyTTL != 24*time.Hour {
+		t.Fatalf("config = %+v, want values from configs/config.yaml", cfg)
+	}
+}
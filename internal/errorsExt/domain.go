@@ -0,0 +1,130 @@
+package errorsExt
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Виды доменных ошибок. По ним HTTP слой выбирает статус ответа,
+// проверять вид ошибки нужно через errors.Is.
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrValidation    = errors.New("validation failed")
+	ErrConflict      = errors.New("conflict")
+	ErrForbidden     = errors.New("forbidden")
+	ErrUnauthorized  = errors.New("unauthorized")
+	ErrUnprocessable = errors.New("unprocessable")
+	ErrUpstream      = errors.New("upstream unavailable")
+	ErrInternal      = errors.New("internal error")
+)
+
+// Стабильные машиночитаемые коды ошибок. Клиенты завязываются на них,
+// поэтому существующие коды не переименовываются.
+const (
+	CodeInternal         = "internal_error"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeBadRequest       = "bad_request"
+
+	CodeInvalidBody             = "invalid_body"
+	CodeRequestValidation       = "request_validation_failed"
+	CodeResponseValidation      = "response_validation_failed"
+	CodeUnauthenticated         = "unauthenticated"
+	CodeInvalidToken            = "invalid_token"
+	CodeInvalidAPIKey           = "invalid_api_key"
+	CodeNotOwner                = "not_owner"
+	CodeInsufficientPermissions = "insufficient_permissions"
+	CodeAccountBanned           = "account_banned"
+
+	CodeIdempotencyInProgress = "idempotency_key_in_progress"
+	CodeIdempotencyKeyReused  = "idempotency_key_reused"
+
+	CodeUserNotFound    = "user_not_found"
+	CodeUserExists      = "user_already_exists"
+	CodePhotoNotFound   = "photo_not_found"
+	CodeTagNotFound     = "tag_not_found"
+	CodeNameRequired    = "name_required"
+	CodeSurnameRequired = "surname_required"
+	CodeInvalidJung     = "invalid_jung_type"
+	CodeInvalidPhoto    = "invalid_photo"
+	CodeTagRequired     = "tag_required"
+	CodeInvalidCursor   = "invalid_cursor"
+
+	CodeInvalidEvent = "invalid_event"
+	CodeUnknownEvent = "unknown_event"
+
+	CodeStorageUnavailable = "storage_unavailable"
+	CodeBrokerUnavailable  = "broker_unavailable"
+)
+
+// FieldError описывает конкретное поле запроса, не прошедшее проверку.
+type FieldError struct {
+	Field   string
+	In      string // path, query, header или body
+	Message string
+}
+
+// Error - доменная ошибка с видом (ErrNotFound, ErrValidation, ...) и стабильным кодом.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+func NotFound(code, message string) *Error {
+	return &Error{Kind: ErrNotFound, Code: code, Message: message}
+}
+
+func Validation(code, message string, fields ...FieldError) *Error {
+	return &Error{Kind: ErrValidation, Code: code, Message: message, Fields: fields}
+}
+
+func Conflict(code, message string) *Error {
+	return &Error{Kind: ErrConflict, Code: code, Message: message}
+}
+
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: ErrForbidden, Code: code, Message: message}
+}
+
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: ErrUnauthorized, Code: code, Message: message}
+}
+
+func Unprocessable(code, message string) *Error {
+	return &Error{Kind: ErrUnprocessable, Code: code, Message: message}
+}
+
+// Upstream оборачивает ошибку внешней зависимости (MinIO, RabbitMQ, ...).
+func Upstream(code, message string, err error) *Error {
+	return &Error{Kind: ErrUpstream, Code: code, Message: message, Err: err}
+}
+
+func Internal(code, message string, err error) *Error {
+	return &Error{Kind: ErrInternal, Code: code, Message: message, Err: err}
+}
+
+// As достает доменную ошибку из цепочки.
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
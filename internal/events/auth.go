@@ -0,0 +1,25 @@
+package events
+
+import (
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/models"
+)
+
+// События сервиса авторизации, на которые подписан профиль. Приходят в том же конверте Envelope.
+const (
+	TypeAccountRegistered = "auth.account.registered"
+	TypeAccountDeleted    = "auth.account.deleted"
+)
+
+// AccountRegistered - новый аккаунт, для него создается профиль.
+type AccountRegistered struct {
+	UserID  uuid.UUID          `json:"user_id"`
+	Name    string             `json:"name"`
+	Surname string             `json:"surname"`
+	Gender  *models.UserGender `json:"gender,omitempty"`
+}
+
+// AccountDeleted - аккаунт удален, профиль удаляется вместе с ним.
+type AccountDeleted struct {
+	UserID uuid.UUID `json:"user_id"`
+}
@@ -0,0 +1,15 @@
+package events
+
+import "context"
+
+type correlationKey struct{}
+
+// WithCorrelationID сохраняет id запроса, который попадет во все события, порожденные им.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
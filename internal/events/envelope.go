@@ -0,0 +1,78 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SpecVersion     = "1.0"
+	Source          = "profile-service"
+	DataContentType = "application/json"
+	ContentType     = "application/cloudevents+json"
+)
+
+// Envelope - конверт события в стиле CloudEvents (structured mode).
+// Получатели различают события по Type и версии схемы data по SchemaVersion.
+type Envelope struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	SchemaVersion   int             `json:"schemaversion"`
+	Time            time.Time       `json:"time"`
+	CorrelationID   string          `json:"correlationid,omitempty"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// New оборачивает data в конверт. Correlation id берется из ctx.
+func New(ctx context.Context, eventType string, data any) (Envelope, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+
+	return Envelope{
+		SpecVersion:     SpecVersion,
+		ID:              uuid.NewString(),
+		Source:          Source,
+		Type:            eventType,
+		SchemaVersion:   SchemaVersion(eventType),
+		Time:            time.Now().UTC(),
+		CorrelationID:   CorrelationID(ctx),
+		DataContentType: DataContentType,
+		Data:            body,
+	}, nil
+}
+
+// legacyTypes читают очереди исходного сервиса: их получатели ждут голый DTO
+// ({user_id,tags}, {user_id,image_url}, {user_id,gender,birth_date}), а не конверт.
+var legacyTypes = map[string]bool{
+	TypeTagsUpdated:  true,
+	TypePhotoUpdated: true,
+	TypeAnketUpdated: true,
+}
+
+// IsLegacy сообщает, что событие уходит в брокер без конверта.
+func IsLegacy(eventType string) bool {
+	return legacyTypes[eventType]
+}
+
+// Body возвращает тело сообщения для брокера и его content-type: конверт целиком, а для
+// устаревших типов - только data в том виде, в каком его отправлял исходный сервис.
+func Body(event Envelope) ([]byte, string, error) {
+	if IsLegacy(event.Type) {
+		return event.Data, DataContentType, nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal message: %w", err)
+	}
+	return body, ContentType, nil
+}
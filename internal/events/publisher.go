@@ -0,0 +1,9 @@
+package events
+
+import "context"
+
+// EventPublisher отправляет событие получателям. Реализации: rabbit.Repo и Recorder.
+// Publish вызывается из outbox.Relay, ошибка означает, что событие нужно отправить повторно.
+type EventPublisher interface {
+	Publish(ctx context.Context, event Envelope) error
+}
@@ -0,0 +1,69 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// Recorder - EventPublisher в памяти. Запоминает опубликованные события, чтобы тесты
+// могли проверить, что именно отправлено, и позволяет запускать сервис без брокера.
+type Recorder struct {
+	mu     sync.Mutex
+	events []Envelope
+	limit  int
+	log    *slog.Logger
+}
+
+// NewRecorder хранит не больше limit последних событий, 0 - без ограничения.
+// Если log не nil, каждое событие пишется в него на уровне Debug.
+func NewRecorder(limit int, log *slog.Logger) *Recorder {
+	return &Recorder{limit: limit, log: log}
+}
+
+func (r *Recorder) Publish(_ context.Context, event Envelope) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+	if r.limit > 0 && len(r.events) > r.limit {
+		r.events = r.events[len(r.events)-r.limit:]
+	}
+
+	if r.log != nil {
+		r.log.Debug("event published",
+			slog.String("type", event.Type),
+			slog.String("id", event.ID),
+			slog.String("data", string(event.Data)))
+	}
+	return nil
+}
+
+// Events возвращает копию записанных событий в порядке публикации.
+func (r *Recorder) Events() []Envelope {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Envelope(nil), r.events...)
+}
+
+// ByType возвращает записанные события одного типа.
+func (r *Recorder) ByType(eventType string) []Envelope {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []Envelope
+	for _, event := range r.events {
+		if event.Type == eventType {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = nil
+}
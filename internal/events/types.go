@@ -0,0 +1,135 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/models"
+)
+
+// Типы событий. Тип одновременно является routing key в topic exchange,
+// поэтому подписаться можно по шаблону, например profile.tags.* или profile.#
+const (
+	TypeUserCreated  = "profile.user.created"
+	TypeUserUpdated  = "profile.user.updated"
+	TypeUserDeleted  = "profile.user.deleted"
+	TypePhotoAdded   = "profile.photo.added"
+	TypePhotoRemoved = "profile.photo.removed"
+	TypeJungUpdated  = "profile.jung.updated"
+
+	// События для сервиса подбора
+	TypeTagsUpdated  = "profile.tags.updated"
+	TypePhotoUpdated = "profile.photo.updated"
+	TypeAnketUpdated = "profile.anket.updated"
+
+	// Теги массивом вместо строки. Отдельный тип, а не новая версия profile.tags.updated,
+	// чтобы старые получатели не получали незнакомый формат
+	TypeTagsUpdatedV2  = "profile.tags.updated.v2"
+	TypeAnketUpdatedV2 = "profile.anket.updated.v2"
+)
+
+// Версии схем data. Несовместимое изменение payload требует новой версии.
+var schemaVersions = map[string]int{
+	TypeUserCreated:  1,
+	TypeUserUpdated:  1,
+	TypeUserDeleted:  1,
+	TypePhotoAdded:   1,
+	TypePhotoRemoved: 1,
+	TypeJungUpdated:  1,
+	TypeTagsUpdated:  1,
+	TypePhotoUpdated: 1,
+	TypeAnketUpdated: 1,
+
+	TypeTagsUpdatedV2:  2,
+	TypeAnketUpdatedV2: 2,
+}
+
+func SchemaVersion(eventType string) int {
+	if v, ok := schemaVersions[eventType]; ok {
+		return v
+	}
+	return 1
+}
+
+// UserCreated - новый профиль.
+type UserCreated struct {
+	UserID    uuid.UUID          `json:"user_id"`
+	Name      string             `json:"name"`
+	Surname   string             `json:"surname"`
+	Gender    *models.UserGender `json:"gender"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// UserUpdated - изменились поля профиля. ChangedFields - имена полей как в API,
+// отсортированы. Новые значения нужно читать через API профиля.
+type UserUpdated struct {
+	UserID        uuid.UUID `json:"user_id"`
+	ChangedFields []string  `json:"changed_fields"`
+}
+
+// UserDeleted - профиль удален вместе с фото и тегами.
+type UserDeleted struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+type PhotoAdded struct {
+	UserID  uuid.UUID `json:"user_id"`
+	PhotoID uuid.UUID `json:"photo_id"`
+	URL     string    `json:"url"`
+}
+
+type PhotoRemoved struct {
+	UserID  uuid.UUID `json:"user_id"`
+	PhotoID uuid.UUID `json:"photo_id"`
+}
+
+// JungUpdated - новый результат теста Юнга.
+type JungUpdated struct {
+	UserID     uuid.UUID `json:"user_id"`
+	JungResult string    `json:"jung_result"`
+	AttemptAt  time.Time `json:"attempt_at"`
+}
+
+// TagsUpdatedV2 - полный набор тегов пользователя. Version растет при каждом изменении
+// набора, получатель отбрасывает события с версией не больше уже примененной.
+type TagsUpdatedV2 struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Version int64     `json:"version"`
+	Tags    []Tag     `json:"tags"`
+}
+
+type Tag struct {
+	ID       uuid.UUID `json:"id"`
+	Value    string    `json:"value"`
+	Category *string   `json:"category"`
+}
+
+// TagsUpdated - актуальный список тегов пользователя через пробел. Устаревший формат:
+// тег из нескольких слов у получателя распадается на несколько.
+type TagsUpdated struct {
+	UserID uuid.UUID `json:"user_id"`
+	Tags   string    `json:"tags"`
+}
+
+// PhotoUpdated - главное фото пользователя.
+type PhotoUpdated struct {
+	UserID   uuid.UUID `json:"user_id"`
+	ImageURL string    `json:"image_url"`
+}
+
+// AnketUpdatedV2 - анкета для сервиса подбора. Публикуется, только когда у пользователя
+// заполнены пол и дата рождения. Дата в RFC 3339, незаполненные необязательные поля - null.
+type AnketUpdatedV2 struct {
+	UserID     uuid.UUID         `json:"user_id"`
+	Gender     models.UserGender `json:"gender"`
+	BirthDate  time.Time         `json:"birth_date"`
+	JungResult *string           `json:"jung_result"`
+}
+
+// AnketUpdated - данные анкеты для сервиса подбора, дата рождения в формате ДД/ММ/ГГГГ.
+// Устаревший формат: пустые пол и дата рождения заменяются на FEMALE и выдуманную дату.
+type AnketUpdated struct {
+	UserID    uuid.UUID         `json:"user_id"`
+	Gender    models.UserGender `json:"gender"`
+	BirthDate string            `json:"birth_date"`
+}
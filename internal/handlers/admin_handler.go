@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/api"
+	"github.com/kerilOvs/profile_sevice/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// AdminHandler - API для администраторов и модераторов.
+// Доступ проверяется политиками маршрутов, здесь берется только actor для аудита.
+type AdminHandler struct {
+	service *service.UserService
+}
+
+func NewAdminHandler(service *service.UserService) *AdminHandler {
+	return &AdminHandler{service: service}
+}
+
+func (h *AdminHandler) AdminListUsers(c echo.Context, params api.AdminListUsersParams) error {
+	users, err := h.service.ListUsers(c.Request().Context(), deref(params.Offset), deref(params.Limit))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, users)
+}
+
+func (h *AdminHandler) AdminGetUser(c echo.Context, id api.UserId) error {
+	user, err := h.service.AdminGetUser(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+func (h *AdminHandler) AdminUpdateUser(c echo.Context, id api.UserId, _ api.AdminUpdateUserParams) error {
+	var req api.AdminUpdateUserJSONRequestBody
+	if err := c.Bind(&req); err != nil {
+		return errInvalidBody
+	}
+
+	if err := h.service.AdminUpdateUser(c.Request().Context(), actorID(c), id, req); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *AdminHandler) AdminDeleteUser(c echo.Context, id api.UserId, _ api.AdminDeleteUserParams) error {
+	if err := h.service.AdminDeleteUser(c.Request().Context(), actorID(c), id); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *AdminHandler) AdminHideUser(c echo.Context, id api.UserId, _ api.AdminHideUserParams) error {
+	return h.setHidden(c, id, true)
+}
+
+func (h *AdminHandler) AdminUnhideUser(c echo.Context, id api.UserId, _ api.AdminUnhideUserParams) error {
+	return h.setHidden(c, id, false)
+}
+
+func (h *AdminHandler) setHidden(c echo.Context, id api.UserId, hidden bool) error {
+	if err := h.service.SetUserHidden(c.Request().Context(), actorID(c), id, hidden); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *AdminHandler) AdminBanUser(c echo.Context, id api.UserId, _ api.AdminBanUserParams) error {
+	var req api.AdminBanUserJSONRequestBody
+	if err := c.Bind(&req); err != nil {
+		return errInvalidBody
+	}
+
+	if err := h.service.BanUser(c.Request().Context(), actorID(c), id, deref(req.Reason)); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *AdminHandler) AdminUnbanUser(c echo.Context, id api.UserId, _ api.AdminUnbanUserParams) error {
+	if err := h.service.UnbanUser(c.Request().Context(), actorID(c), id); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *AdminHandler) AdminRemoveUserPhoto(c echo.Context, userID api.UserId, photoID api.PhotoId, _ api.AdminRemoveUserPhotoParams) error {
+	if err := h.service.AdminRemovePhoto(c.Request().Context(), actorID(c), userID, photoID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *AdminHandler) AdminRemoveUserTag(c echo.Context, userID api.UserId, tagID api.TagId, _ api.AdminRemoveUserTagParams) error {
+	if err := h.service.AdminRemoveTag(c.Request().Context(), actorID(c), userID, tagID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *AdminHandler) AdminListAuditRecords(c echo.Context, params api.AdminListAuditRecordsParams) error {
+	records, err := h.service.ListAuditRecords(c.Request().Context(), params.UserId, deref(params.Offset), deref(params.Limit))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, records)
+}
+
+func (h *AdminHandler) AdminSearchUsers(c echo.Context, params api.AdminSearchUsersParams) error {
+	hits, err := h.service.SearchUsersFullText(c.Request().Context(), params.Q, deref(params.Offset), deref(params.Limit))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, hits)
+}
+
+func actorID(c echo.Context) uuid.UUID {
+	principal, ok := PrincipalFrom(c)
+	if !ok {
+		return uuid.Nil
+	}
+	return principal.UserID
+}
+
+func deref[T any](v *T) T {
+	if v == nil {
+		var zero T
+		return zero
+	}
+	return *v
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/auth"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/kerilOvs/profile_sevice/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+const principalKey = "principal"
+
+var (
+	errUnauthenticated         = errorsExt.Unauthorized(errorsExt.CodeUnauthenticated, "Authentication required")
+	errInsufficientPermissions = errorsExt.Forbidden(errorsExt.CodeInsufficientPermissions, "Insufficient permissions")
+)
+
+// Authenticate проверяет API ключ сервиса или JWT пользователя (если они переданы)
+// и кладет Principal в контекст. Запросы без учетных данных проходят дальше анонимно,
+// доступ решают политики маршрутов.
+func Authenticate(verifier *auth.Verifier, apiKeys *auth.APIKeys) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if key := c.Request().Header.Get(auth.APIKeyHeader); key != "" {
+				principal, err := apiKeys.Authenticate(key)
+				if err != nil {
+					return errorsExt.Unauthorized(errorsExt.CodeInvalidAPIKey, "Invalid API key")
+				}
+
+				c.Set(principalKey, principal)
+				return next(c)
+			}
+
+			principal, err := verifier.Authenticate(c.Request())
+			if errors.Is(err, auth.ErrNoToken) {
+				return next(c)
+			}
+			if err != nil {
+				return errorsExt.Unauthorized(errorsExt.CodeInvalidToken, "Invalid JWT token")
+			}
+
+			c.Set(principalKey, principal)
+			return next(c)
+		}
+	}
+}
+
+// PrincipalFrom возвращает Principal, положенный в контекст Authenticate.
+func PrincipalFrom(c echo.Context) (*auth.Principal, bool) {
+	principal, ok := c.Get(principalKey).(*auth.Principal)
+	return principal, ok
+}
+
+// RequireAuth пропускает только аутентифицированные запросы.
+func RequireAuth() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, ok := PrincipalFrom(c); !ok {
+				return errUnauthenticated
+			}
+			return next(c)
+		}
+	}
+}
+
+// OwnerOnly пропускает запрос, только если id из параметра пути совпадает с пользователем из токена.
+func OwnerOnly(param string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := PrincipalFrom(c)
+			if !ok {
+				return errUnauthenticated
+			}
+
+			requestedID, err := uuid.Parse(c.Param(param))
+			if err != nil {
+				return errorsExt.Validation(errorsExt.CodeRequestValidation, "Invalid user ID",
+					errorsExt.FieldError{Field: param, In: "path", Message: "must be a valid uuid"})
+			}
+
+			if requestedID != principal.UserID {
+				return errorsExt.Forbidden(errorsExt.CodeNotOwner, "You can only access your own data")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RequireRole пропускает пользователей, у которых есть хотя бы одна из ролей.
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := PrincipalFrom(c)
+			if !ok {
+				return errUnauthenticated
+			}
+
+			if !principal.HasRole(roles...) {
+				return errInsufficientPermissions
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RequireServiceScope требует у внутреннего сервиса нужный скоуп.
+// Запросы пользователей пропускаются, их ограничивает сам обработчик.
+func RequireServiceScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := PrincipalFrom(c)
+			if !ok {
+				return errUnauthenticated
+			}
+
+			if principal.IsService() && !principal.HasScope(scope) {
+				return errInsufficientPermissions
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RequireServiceScopeOrRole пускает внутренний сервис с нужным скоупом
+// или пользователя с одной из ролей.
+func RequireServiceScopeOrRole(scope string, roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := PrincipalFrom(c)
+			if !ok {
+				return errUnauthenticated
+			}
+
+			allowed := principal.HasRole(roles...)
+			if principal.IsService() {
+				allowed = principal.HasScope(scope)
+			}
+			if !allowed {
+				return errInsufficientPermissions
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// NotBanned не дает забаненному пользователю менять свой профиль.
+func NotBanned(userService *service.UserService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := PrincipalFrom(c)
+			if !ok {
+				return next(c)
+			}
+
+			banned, err := userService.IsBanned(c.Request().Context(), principal.UserID)
+			if err != nil {
+				return err
+			}
+			if banned {
+				return errorsExt.Forbidden(errorsExt.CodeAccountBanned, "Account is banned")
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kerilOvs/profile_sevice/internal/auth"
+	"github.com/kerilOvs/profile_sevice/internal/config"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/labstack/echo/v4"
+)
+
+// Ключи сервисов: creator может только создавать пользователей, reader - только читать
+const (
+	creatorKey = "creator-key"
+	readerKey  = "reader-key"
+)
+
+func newAuthEcho(t *testing.T) *echo.Echo {
+	t.Helper()
+
+	hash := func(key string) string {
+		sum := sha256.Sum256([]byte(key))
+		return hex.EncodeToString(sum[:])
+	}
+	apiKeys, err := auth.NewAPIKeys([]config.APIKeyConfig{
+		{Name: "creator", KeyHash: hash(creatorKey), Scopes: []string{auth.ScopeUsersCreate}},
+		{Name: "reader", KeyHash: hash(readerKey), Scopes: []string{auth.ScopeUsersRead}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := auth.NewVerifier(config.AuthConfig{HMACSecret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusNoContent) }
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	e.Use(Authenticate(verifier, apiKeys))
+	e.POST("/users", ok, RequireServiceScope(auth.ScopeUsersCreate))
+	e.GET("/admin/users", ok, RequireServiceScopeOrRole(auth.ScopeUsersRead, auth.RoleAdmin))
+	return e
+}
+
+func TestServiceScopes(t *testing.T) {
+	e := newAuthEcho(t)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		key      string
+		wantCode int
+		wantErr  string
+	}{
+		{"scope granted", http.MethodPost, "/users", creatorKey, http.StatusNoContent, ""},
+		{"scope missing", http.MethodPost, "/users", readerKey, http.StatusForbidden, errorsExt.CodeInsufficientPermissions},
+		{"scope or role granted", http.MethodGet, "/admin/users", readerKey, http.StatusNoContent, ""},
+		{"scope or role missing", http.MethodGet, "/admin/users", creatorKey, http.StatusForbidden, errorsExt.CodeInsufficientPermissions},
+		{"unknown key", http.MethodPost, "/users", "stolen-key", http.StatusUnauthorized, errorsExt.CodeInvalidAPIKey},
+		{"no credentials", http.MethodPost, "/users", "", http.StatusUnauthorized, errorsExt.CodeUnauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set(auth.APIKeyHeader, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.wantCode, rec.Body)
+			}
+			if tt.wantErr != "" {
+				if problem := decodeProblem(t, rec); problem.Code != tt.wantErr {
+					t.Fatalf("code = %q, want %q", problem.Code, tt.wantErr)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/kerilOvs/profile_sevice/internal/api"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:profile-service:problem:"
+)
+
+// ErrorHandler отдает любые ошибки обработчиков в формате RFC 7807 (application/problem+json).
+// Статус выбирается по виду доменной ошибки, неизвестные ошибки превращаются в 500 без подробностей.
+func ErrorHandler(log *slog.Logger) echo.HTTPErrorHandler {
+	log = log.WithGroup("http_server")
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		// Саму ошибку логирует Logging, здесь только формируем ответ
+		problem := problemFrom(err)
+		problem.Instance = ptr(c.Request().URL.Path)
+
+		var writeErr error
+		if c.Request().Method == http.MethodHead {
+			writeErr = c.NoContent(problem.Status)
+		} else {
+			writeErr = writeProblem(c, problem)
+		}
+		if writeErr != nil {
+			log.ErrorContext(c.Request().Context(), "failed to write error response", slog.Any("error", writeErr))
+		}
+	}
+}
+
+func writeProblem(c echo.Context, problem api.Problem) error {
+	c.Response().Header().Set(echo.HeaderContentType, problemContentType)
+	c.Response().WriteHeader(problem.Status)
+	return c.Echo().JSONSerializer.Serialize(c, problem, "")
+}
+
+func problemFrom(err error) api.Problem {
+	if domainErr, ok := errorsExt.As(err); ok {
+		// В detail идет только Message, текст обернутой ошибки остается в логах
+		problem := newProblem(problemStatus(err), domainErr.Code)
+		problem.Detail = ptr(domainErr.Message)
+		if len(domainErr.Fields) > 0 {
+			fields := make([]api.FieldError, 0, len(domainErr.Fields))
+			for _, f := range domainErr.Fields {
+				fields = append(fields, api.FieldError{Field: f.Field, In: api.FieldErrorIn(f.In), Message: f.Message})
+			}
+			problem.Fields = &fields
+		}
+		return problem
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		problem := newProblem(httpErr.Code, codeForStatus(httpErr.Code))
+		if msg, ok := httpErr.Message.(string); ok && httpErr.Code < http.StatusInternalServerError {
+			problem.Detail = ptr(msg)
+		}
+		return problem
+	}
+
+	return newProblem(http.StatusInternalServerError, errorsExt.CodeInternal)
+}
+
+// problemStatus возвращает HTTP статус, которым будет отдана ошибка.
+func problemStatus(err error) int {
+	var httpErr *echo.HTTPError
+	switch {
+	case errors.Is(err, errorsExt.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errorsExt.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, errorsExt.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, errorsExt.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, errorsExt.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, errorsExt.ErrUnprocessable):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, errorsExt.ErrUpstream):
+		return http.StatusBadGateway
+	case errors.As(err, &httpErr):
+		return httpErr.Code
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func newProblem(status int, code string) api.Problem {
+	return api.Problem{
+		Type:   problemTypePrefix + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+	}
+}
+
+// codeForStatus строит код для ошибок самого echo (404 маршрута, 405, 413, ...).
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusNotFound:
+		return errorsExt.CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return errorsExt.CodeMethodNotAllowed
+	case http.StatusBadRequest:
+		return errorsExt.CodeBadRequest
+	case http.StatusInternalServerError:
+		return errorsExt.CodeInternal
+	}
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
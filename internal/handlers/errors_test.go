@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/labstack/echo/v4"
+)
+
+func serveError(t *testing.T, method string, err error) *httptest.ResponseRecorder {
+	t.Helper()
+
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	e.Add(method, "/users/:id", func(c echo.Context) error { return err })
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(method, "/users/42", nil))
+	return rec
+}
+
+func TestErrorHandlerMapsDomainErrors(t *testing.T) {
+	secret := errors.New("dial tcp 10.0.0.5:9000: connection refused")
+
+	tests := []struct {
+		err        error
+		wantStatus int
+		wantCode   string
+		wantDetail string
+	}{
+		{errorsExt.NotFound(errorsExt.CodeUserNotFound, "User not found"), http.StatusNotFound, errorsExt.CodeUserNotFound, "User not found"},
+		{errorsExt.Validation(errorsExt.CodeNameRequired, "Name is required"), http.StatusBadRequest, errorsExt.CodeNameRequired, "Name is required"},
+		{errorsExt.Unauthorized(errorsExt.CodeInvalidToken, "Invalid JWT token"), http.StatusUnauthorized, errorsExt.CodeInvalidToken, "Invalid JWT token"},
+		{errorsExt.Forbidden(errorsExt.CodeNotOwner, "Not yours"), http.StatusForbidden, errorsExt.CodeNotOwner, "Not yours"},
+		{errorsExt.Conflict(errorsExt.CodeUserExists, "User exists"), http.StatusConflict, errorsExt.CodeUserExists, "User exists"},
+		{errorsExt.Unprocessable(errorsExt.CodeIdempotencyKeyReused, "Key reused"), http.StatusUnprocessableEntity, errorsExt.CodeIdempotencyKeyReused, "Key reused"},
+		{errorsExt.Upstream(errorsExt.CodeStorageUnavailable, "Storage unavailable", secret), http.StatusBadGateway, errorsExt.CodeStorageUnavailable, "Storage unavailable"},
+		{errorsExt.Internal(errorsExt.CodeInternal, "Failed to save", secret), http.StatusInternalServerError, errorsExt.CodeInternal, "Failed to save"},
+		// Обертка вокруг доменной ошибки не меняет ответ
+		{fmt.Errorf("get user: %w", errorsExt.NotFound(errorsExt.CodeUserNotFound, "User not found")), http.StatusNotFound, errorsExt.CodeUserNotFound, "User not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantCode, func(t *testing.T) {
+			rec := serveError(t, http.MethodGet, tt.err)
+
+			problem := decodeProblem(t, rec)
+			if rec.Code != tt.wantStatus || problem.Status != tt.wantStatus {
+				t.Fatalf("status %d (body %d), want %d", rec.Code, problem.Status, tt.wantStatus)
+			}
+			if problem.Code != tt.wantCode || problem.Type != problemTypePrefix+tt.wantCode {
+				t.Fatalf("code %q, type %q, want %q", problem.Code, problem.Type, tt.wantCode)
+			}
+			if problem.Title != http.StatusText(tt.wantStatus) || problem.Instance == nil || *problem.Instance != "/users/42" {
+				t.Fatalf("problem = %+v", problem)
+			}
+			if problem.Detail == nil || *problem.Detail != tt.wantDetail {
+				t.Fatalf("detail = %v, want %q", problem.Detail, tt.wantDetail)
+			}
+			if strings.Contains(rec.Body.String(), "10.0.0.5") {
+				t.Fatalf("wrapped error leaked to the client: %s", rec.Body)
+			}
+		})
+	}
+}
+
+func TestErrorHandlerFields(t *testing.T) {
+	err := errorsExt.Validation(errorsExt.CodeRequestValidation, "Request validation failed",
+		errorsExt.FieldError{Field: "gender", In: "body", Message: "value is not one of the allowed values"})
+
+	problem := decodeProblem(t, serveError(t, http.MethodGet, err))
+	if problem.Fields == nil || len(*problem.Fields) != 1 {
+		t.Fatalf("fields = %v", problem.Fields)
+	}
+	if f := (*problem.Fields)[0]; f.Field != "gender" || f.In != "body" || f.Message == "" {
+		t.Fatalf("field = %+v", f)
+	}
+}
+
+func TestErrorHandlerEchoErrors(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	e.GET("/users/:id", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+	e.POST("/upload", func(c echo.Context) error { return echo.ErrStatusRequestEntityTooLarge })
+
+	tests := []struct {
+		method     string
+		path       string
+		wantStatus int
+		wantCode   string
+	}{
+		{http.MethodGet, "/missing", http.StatusNotFound, errorsExt.CodeNotFound},
+		{http.MethodDelete, "/users/42", http.StatusMethodNotAllowed, errorsExt.CodeMethodNotAllowed},
+		{http.MethodPost, "/upload", http.StatusRequestEntityTooLarge, "request_entity_too_large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantCode, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			problem := decodeProblem(t, rec)
+			if rec.Code != tt.wantStatus || problem.Code != tt.wantCode || problem.Type != problemTypePrefix+tt.wantCode {
+				t.Fatalf("status %d, problem %+v, want %d %q", rec.Code, problem, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestErrorHandlerHidesUnknownErrors(t *testing.T) {
+	rec := serveError(t, http.MethodGet, errors.New("pq: password authentication failed for user profile"))
+
+	problem := decodeProblem(t, rec)
+	if rec.Code != http.StatusInternalServerError || problem.Code != errorsExt.CodeInternal {
+		t.Fatalf("status %d, problem %+v", rec.Code, problem)
+	}
+	if problem.Detail != nil || strings.Contains(rec.Body.String(), "password") {
+		t.Fatalf("internal error leaked to the client: %s", rec.Body)
+	}
+}
+
+func TestErrorHandlerHeadHasNoBody(t *testing.T) {
+	rec := serveError(t, http.MethodHead, errorsExt.NotFound(errorsExt.CodeUserNotFound, "User not found"))
+
+	if rec.Code != http.StatusNotFound || rec.Body.Len() != 0 {
+		t.Fatalf("status %d, body %q, want 404 without a body", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/kerilOvs/profile_sevice/internal/api"
+	"github.com/labstack/echo/v4"
+)
+
+const readinessTimeout = 2 * time.Second
+
+// ReadinessCheck проверяет внешнюю зависимость (БД, брокер).
+type ReadinessCheck func(ctx context.Context) error
+
+// HealthHandler отвечает на проверки живости и готовности.
+type HealthHandler struct {
+	checks map[string]ReadinessCheck
+}
+
+func NewHealthHandler(checks map[string]ReadinessCheck) *HealthHandler {
+	return &HealthHandler{checks: checks}
+}
+
+func (h *HealthHandler) Healthy(c echo.Context) error {
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *HealthHandler) Ready(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), readinessTimeout)
+	defer cancel()
+
+	names := make([]string, 0, len(h.checks))
+	for name := range h.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resp := api.Readiness{Status: api.Ok, Checks: make(map[string]string, len(names))}
+	for _, name := range names {
+		if err := h.checks[name](ctx); err != nil {
+			resp.Status = api.Unavailable
+			resp.Checks[name] = err.Error()
+			continue
+		}
+		resp.Checks[name] = "ok"
+	}
+
+	if resp.Status != api.Ok {
+		return c.JSON(http.StatusServiceUnavailable, resp)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/kerilOvs/profile_sevice/internal/models"
+	"github.com/kerilOvs/profile_sevice/internal/storage"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+
+	defaultIdempotencyTTL   = 24 * time.Hour
+	defaultIdempotencyLease = time.Minute
+	// Тело запроса с ключом читается в память ради хэша. Самый большой запрос - загрузка фото
+	defaultIdempotencyMaxBody = 10 << 20
+)
+
+var errIdempotencyInProgress = errorsExt.Conflict(errorsExt.CodeIdempotencyInProgress, "Request with this Idempotency-Key is in progress")
+
+// Idempotency повторяет сохраненный ответ для запросов с тем же Idempotency-Key.
+// Ключ уникален в пределах (пользователь, ключ, маршрут); повтор ключа с другим телом отклоняется.
+// Готовый ответ хранится ttl, а незавершенный запрос держит ключ только lease: если процесс
+// упал посреди запроса, клиент сможет повторить его с тем же ключом, не дожидаясь ttl.
+type Idempotency struct {
+	storage storage.IdempotencyStorage
+	ttl     time.Duration
+	lease   time.Duration
+	maxBody int64
+	log     *slog.Logger
+}
+
+func NewIdempotency(storage storage.IdempotencyStorage, ttl, lease time.Duration, maxBody int64, log *slog.Logger) *Idempotency {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	if lease <= 0 {
+		lease = defaultIdempotencyLease
+	}
+	if maxBody <= 0 {
+		maxBody = defaultIdempotencyMaxBody
+	}
+	return &Idempotency{storage: storage, ttl: ttl, lease: lease, maxBody: maxBody, log: log.WithGroup("idempotency")}
+}
+
+func (i *Idempotency) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				return next(c)
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(c.Response(), c.Request().Body, i.maxBody))
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					return echo.ErrStatusRequestEntityTooLarge
+				}
+				return errInvalidBody
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			hash := sha256.Sum256(body)
+			record := &models.IdempotencyRecord{
+				Owner:       idempotencyOwner(c),
+				Key:         key,
+				Route:       c.Request().Method + " " + c.Request().URL.Path,
+				RequestHash: hex.EncodeToString(hash[:]),
+				CreatedAt:   time.Now(),
+			}
+
+			ctx := c.Request().Context()
+			created, err := i.acquire(ctx, record)
+			if err != nil {
+				return err
+			}
+			if !created {
+				return i.replay(c, record)
+			}
+
+			rec := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = rec
+
+			err = next(c)
+			if err != nil {
+				// Ответ об ошибке пишем сразу, чтобы 4xx сохранились вместе с ключом
+				c.Error(err)
+			}
+
+			// Результат сохраняем, даже если клиент уже отключился
+			ctx = context.WithoutCancel(ctx)
+
+			// Ошибки сервера не запоминаем, чтобы клиент мог повторить запрос с тем же ключом
+			status := c.Response().Status
+			if status >= http.StatusInternalServerError {
+				if delErr := i.storage.DeleteRecord(ctx, record.Owner, record.Key, record.Route); delErr != nil {
+					i.log.ErrorContext(ctx, "failed to release idempotency key", slog.Any("error", delErr))
+				}
+				return err
+			}
+
+			now := time.Now()
+			record.StatusCode = status
+			record.ContentType = c.Response().Header().Get(echo.HeaderContentType)
+			record.Body = rec.body.Bytes()
+			record.CompletedAt = &now
+			if saveErr := i.storage.CompleteRecord(ctx, record); saveErr != nil {
+				i.log.ErrorContext(ctx, "failed to save idempotent response", slog.Any("error", saveErr))
+			}
+
+			return err
+		}
+	}
+}
+
+// acquire занимает ключ. Просроченную запись с тем же ключом заменяет новой, как и брошенную:
+// незавершенную дольше lease с тем же телом запроса.
+func (i *Idempotency) acquire(ctx context.Context, record *models.IdempotencyRecord) (bool, error) {
+	created, err := i.storage.CreateRecord(ctx, record)
+	if err != nil || created {
+		return created, err
+	}
+
+	stored, err := i.storage.GetRecord(ctx, record.Owner, record.Key, record.Route)
+	if err != nil || stored == nil {
+		return false, err
+	}
+	age := time.Since(stored.CreatedAt)
+	abandoned := stored.CompletedAt == nil && stored.RequestHash == record.RequestHash && age > i.lease
+	if age <= i.ttl && !abandoned {
+		return false, nil
+	}
+
+	if err := i.storage.DeleteRecord(ctx, stored.Owner, stored.Key, stored.Route); err != nil {
+		return false, err
+	}
+	return i.storage.CreateRecord(ctx, record)
+}
+
+func (i *Idempotency) replay(c echo.Context, record *models.IdempotencyRecord) error {
+	stored, err := i.storage.GetRecord(c.Request().Context(), record.Owner, record.Key, record.Route)
+	if err != nil {
+		return err
+	}
+	if stored == nil {
+		// Запись успели удалить (первый запрос упал) - просим клиента повторить
+		return errIdempotencyInProgress
+	}
+
+	if stored.RequestHash != record.RequestHash {
+		return errorsExt.Unprocessable(errorsExt.CodeIdempotencyKeyReused, "Idempotency-Key was already used with a different payload")
+	}
+
+	if stored.CompletedAt == nil {
+		return errIdempotencyInProgress
+	}
+
+	c.Response().Header().Set(idempotencyReplayedHeader, "true")
+	if len(stored.Body) == 0 {
+		return c.NoContent(stored.StatusCode)
+	}
+	return c.Blob(stored.StatusCode, stored.ContentType, stored.Body)
+}
+
+// Cleanup периодически удаляет записи старше TTL, пока не отменен ctx.
+func (i *Idempotency) Cleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := i.storage.DeleteRecordsBefore(ctx, time.Now().Add(-i.ttl)); err != nil {
+				i.log.ErrorContext(ctx, "failed to clean up idempotency keys", slog.Any("error", err))
+			}
+		}
+	}
+}
+
+func idempotencyOwner(c echo.Context) string {
+	principal, ok := PrincipalFrom(c)
+	if !ok {
+		return "anonymous"
+	}
+	if principal.IsService() {
+		return "service:" + principal.Service
+	}
+	return principal.UserID.String()
+}
+
+// responseRecorder пишет ответ клиенту и параллельно сохраняет тело.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := r.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("hijack is not supported")
+}
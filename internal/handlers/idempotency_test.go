@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kerilOvs/profile_sevice/internal/models"
+	"github.com/kerilOvs/profile_sevice/internal/storage/memory"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	testTTL   = time.Hour
+	testLease = time.Minute
+)
+
+type idempotencyServer struct {
+	e       *echo.Echo
+	storage *memory.IdempotencyMemoryStorage
+	calls   atomic.Int32
+	// handler подменяет ответ обработчика, по умолчанию 201 с номером вызова
+	handler func(c echo.Context) error
+}
+
+func newIdempotencyServer(t *testing.T) *idempotencyServer {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := &idempotencyServer{e: echo.New(), storage: memory.NewIdempotencyMemoryStorage()}
+	s.e.HTTPErrorHandler = ErrorHandler(log)
+
+	idempotency := NewIdempotency(s.storage, testTTL, testLease, 64, log)
+	s.e.POST("/users/:id/tag", func(c echo.Context) error {
+		n := s.calls.Add(1)
+		if s.handler != nil {
+			return s.handler(c)
+		}
+		body, _ := io.ReadAll(c.Request().Body)
+		return c.JSON(http.StatusCreated, map[string]any{"call": n, "body": string(body)})
+	}, idempotency.Middleware())
+	return s
+}
+
+func (s *idempotencyServer) do(key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/users/42/tag", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	s.e.ServeHTTP(rec, req)
+	return rec
+}
+
+// record кладет в хранилище запись ключа k1, как будто ее оставил предыдущий запрос.
+func (s *idempotencyServer) record(t *testing.T, body string, age time.Duration, completed bool) {
+	t.Helper()
+
+	hash := sha256.Sum256([]byte(body))
+	record := &models.IdempotencyRecord{
+		Owner:       "anonymous",
+		Key:         "k1",
+		Route:       "POST /users/42/tag",
+		RequestHash: hex.EncodeToString(hash[:]),
+		CreatedAt:   time.Now().Add(-age),
+	}
+	if completed {
+		at := record.CreatedAt
+		record.StatusCode, record.ContentType, record.Body, record.CompletedAt = http.StatusCreated, echo.MIMEApplicationJSON, []byte(`{"old":true}`), &at
+	}
+	if created, err := s.storage.CreateRecord(context.Background(), record); err != nil || !created {
+		t.Fatalf("CreateRecord = %v, %v", created, err)
+	}
+}
+
+func TestIdempotencyReplaysStoredResponse(t *testing.T) {
+	s := newIdempotencyServer(t)
+
+	first := s.do("k1", `{"value":"chess"}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first: status %d: %s", first.Code, first.Body)
+	}
+	second := s.do("k1", `{"value":"chess"}`)
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Fatalf("replay: status %d, body %s, want %s", second.Code, second.Body, first.Body)
+	}
+	if second.Header().Get(idempotencyReplayedHeader) != "true" || first.Header().Get(idempotencyReplayedHeader) != "" {
+		t.Fatal("Idempotent-Replayed header is set only on the replay")
+	}
+	if got := s.calls.Load(); got != 1 {
+		t.Fatalf("handler calls = %d, want 1", got)
+	}
+
+	// Без ключа и с другим ключом запрос выполняется заново
+	s.do("", `{"value":"chess"}`)
+	s.do("k2", `{"value":"chess"}`)
+	if got := s.calls.Load(); got != 3 {
+		t.Fatalf("handler calls = %d, want 3", got)
+	}
+}
+
+func TestIdempotencyRejectsDifferentPayload(t *testing.T) {
+	s := newIdempotencyServer(t)
+	s.do("k1", `{"value":"chess"}`)
+
+	rec := s.do("k1", `{"value":"go"}`)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "idempotency_key_reused") {
+		t.Fatalf("status %d: %s, want 422 idempotency_key_reused", rec.Code, rec.Body)
+	}
+	if got := s.calls.Load(); got != 1 {
+		t.Fatalf("handler calls = %d, want 1", got)
+	}
+}
+
+func TestIdempotencyConcurrentRequestInProgress(t *testing.T) {
+	s := newIdempotencyServer(t)
+	started, release := make(chan struct{}), make(chan struct{})
+	s.handler = func(c echo.Context) error {
+		close(started)
+		<-release
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- s.do("k1", `{}`) }()
+	<-started
+
+	rec := s.do("k1", `{}`)
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "idempotency_key_in_progress") {
+		t.Fatalf("concurrent: status %d: %s, want 409", rec.Code, rec.Body)
+	}
+
+	close(release)
+	if first := <-done; first.Code != http.StatusNoContent {
+		t.Fatalf("first: status %d", first.Code)
+	}
+	if rec := s.do("k1", `{}`); rec.Code != http.StatusNoContent || rec.Header().Get(idempotencyReplayedHeader) != "true" {
+		t.Fatalf("after completion: status %d, want replayed 204", rec.Code)
+	}
+}
+
+func TestIdempotencyExpiry(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		age       time.Duration
+		completed bool
+		wantCalls int32
+		wantCode  int
+	}{
+		{"completed within ttl is replayed", `{}`, testTTL / 2, true, 0, http.StatusCreated},
+		{"completed after ttl runs again", `{}`, testTTL + time.Minute, true, 1, http.StatusCreated},
+		{"in progress within lease", `{}`, testLease / 2, false, 0, http.StatusConflict},
+		{"abandoned after lease runs again", `{}`, testLease + time.Second, false, 1, http.StatusCreated},
+		{"abandoned with another payload", `{"other":1}`, testLease + time.Second, false, 0, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newIdempotencyServer(t)
+			s.record(t, tt.body, tt.age, tt.completed)
+
+			rec := s.do("k1", `{}`)
+			if rec.Code != tt.wantCode || s.calls.Load() != tt.wantCalls {
+				t.Fatalf("status %d, handler calls %d, want %d and %d: %s", rec.Code, s.calls.Load(), tt.wantCode, tt.wantCalls, rec.Body)
+			}
+		})
+	}
+}
+
+func TestIdempotencyLimitsBody(t *testing.T) {
+	s := newIdempotencyServer(t)
+
+	rec := s.do("k1", `{"value":"`+strings.Repeat("x", 100)+`"}`)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status %d: %s, want 413", rec.Code, rec.Body)
+	}
+	if got := s.calls.Load(); got != 0 {
+		t.Fatalf("handler calls = %d, want 0", got)
+	}
+	if stored, _ := s.storage.GetRecord(context.Background(), "anonymous", "k1", "POST /users/42/tag"); stored != nil {
+		t.Fatalf("key taken by a rejected request: %+v", stored)
+	}
+}
+
+func TestIdempotencyServerErrorsAreNotStored(t *testing.T) {
+	s := newIdempotencyServer(t)
+	s.handler = func(c echo.Context) error {
+		if s.calls.Load() == 1 {
+			return echo.ErrInternalServerError
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	if rec := s.do("k1", `{}`); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("first: status %d", rec.Code)
+	}
+	if rec := s.do("k1", `{}`); rec.Code != http.StatusNoContent || rec.Header().Get(idempotencyReplayedHeader) != "" {
+		t.Fatalf("retry: status %d, want the handler to run again", rec.Code)
+	}
+}
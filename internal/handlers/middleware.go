@@ -2,11 +2,43 @@ package handlers
 
 import (
 	"log/slog"
+	"net/http"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/events"
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	CorrelationIDHeader = "X-Correlation-ID"
+
+	maxCorrelationIDLen = 128
+)
+
+// CorrelationID берет id из X-Correlation-ID (или X-Request-ID), а если его нет - создает новый.
+// Id возвращается в ответе и попадает в события, порожденные запросом.
+func CorrelationID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			id := req.Header.Get(CorrelationIDHeader)
+			if id == "" {
+				id = req.Header.Get(echo.HeaderXRequestID)
+			}
+			if id == "" || len(id) > maxCorrelationIDLen {
+				id = uuid.NewString()
+			}
+
+			c.Response().Header().Set(CorrelationIDHeader, id)
+			c.SetRequest(req.WithContext(events.WithCorrelationID(req.Context(), id)))
+
+			return next(c)
+		}
+	}
+}
+
 func Logging(log *slog.Logger) echo.MiddlewareFunc {
 	log = log.WithGroup("http_server")
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -14,17 +46,23 @@ func Logging(log *slog.Logger) echo.MiddlewareFunc {
 			start := time.Now()
 			err := next(c)
 			if err != nil {
-				log.ErrorContext(c.Request().Context(),
+				// Ошибки клиента (4xx) ожидаемы и не должны засорять лог ошибок
+				level := slog.LevelError
+				if problemStatus(err) < http.StatusInternalServerError {
+					level = slog.LevelInfo
+				}
+				log.Log(c.Request().Context(), level,
 					"error during request",
 					slog.Any("error", err),
 					slog.Group(
 						"request",
 						slog.String("method", c.Request().Method),
 						slog.String("path", c.Request().URL.Path),
-						slog.Int("status", c.Response().Status),
+						slog.Int("status", problemStatus(err)),
 						slog.Duration("dur", time.Since(start)),
 						slog.String("remote_ip", c.Request().RemoteAddr),
 						slog.String("user_agent", c.Request().UserAgent()),
+						slog.String("correlation_id", events.CorrelationID(c.Request().Context())),
 					))
 			} else {
 				log.DebugContext(c.Request().Context(),
@@ -36,6 +74,7 @@ func Logging(log *slog.Logger) echo.MiddlewareFunc {
 						slog.Duration("dur", time.Since(start)),
 						slog.String("remote_ip", c.Request().RemoteAddr),
 						slog.String("user_agent", c.Request().UserAgent()),
+						slog.String("correlation_id", events.CorrelationID(c.Request().Context())),
 					))
 			}
 			return err
@@ -4,7 +4,8 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/api"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
 	"github.com/kerilOvs/profile_sevice/internal/service"
 	"github.com/labstack/echo/v4"
 )
@@ -25,46 +26,43 @@ func NewPhotoHandler(userService *service.UserService, photoService *service.Pho
 // @Accept  multipart/form-data
 // @Param   photo formData file true "Фото пользователя"
 // @Success 201 {object} models.UserPhoto
-func (h *PhotoHandler) UploadPhoto(c echo.Context) error {
-	userID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("invalid user id"))
-	}
-
+func (h *PhotoHandler) UploadPhoto(c echo.Context, userID api.UserId, _ api.UploadPhotoParams) error {
 	// Получаем файл из формы
 	file, err := c.FormFile("photo")
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("photo is required"))
+		return errorsExt.Validation(errorsExt.CodeInvalidPhoto, "photo is required",
+			errorsExt.FieldError{Field: "photo", In: "body", Message: "photo is required"})
 	}
 
 	// Проверяем тип файла
 	if !isValidImageType(file.Header.Get("Content-Type")) {
-		return c.JSON(http.StatusBadRequest, errorResponse("only jpeg/png images are allowed"))
+		return errorsExt.Validation(errorsExt.CodeInvalidPhoto, "only jpeg/png images are allowed",
+			errorsExt.FieldError{Field: "photo", In: "body", Message: "only jpeg/png images are allowed"})
 	}
 
 	// Открываем файл
 	src, err := file.Open()
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, errorResponse("failed to read file"))
+		return errorsExt.Internal(errorsExt.CodeInternal, "failed to read file", err)
 	}
 	defer src.Close()
 
 	// Загружаем фото в MinIO
 	objectName, err := h.photoService.UploadPhoto(c.Request().Context(), src, file.Size)
 	if err != nil {
-		return errorResponseWithCode(c, err, http.StatusInternalServerError)
+		return err
 	}
 
 	// Получаем URL для доступа к фото
 	photoURL, err := h.photoService.GetPhotoURL(objectName, 24*time.Hour)
 	if err != nil {
-		return errorResponseWithCode(c, err, http.StatusInternalServerError)
+		return err
 	}
 
 	// Сохраняем информацию о фото в БД
-	photo, err := h.userService.AddUserPhoto(userID, photoURL)
+	photo, err := h.userService.AddUserPhoto(c.Request().Context(), userID, photoURL)
 	if err != nil {
-		return errorResponseWithCode(c, err, http.StatusInternalServerError)
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, photo)
@@ -75,11 +73,10 @@ func (h *PhotoHandler) UploadPhoto(c echo.Context) error {
 // @Param   id path string true "ID фото"
 // @Success 200
 // @Header  200 {string} Content-Type "image/jpeg"
-func (h *PhotoHandler) GetPhoto(c echo.Context) error {
-	objectName := c.Param("id")
+func (h *PhotoHandler) GetPhoto(c echo.Context, objectName string) error {
 	url, err := h.photoService.GetPhotoURL(objectName, 24*time.Hour)
 	if err != nil {
-		return errorResponseWithCode(c, err, http.StatusNotFound)
+		return err
 	}
 
 	return c.Redirect(http.StatusTemporaryRedirect, url)
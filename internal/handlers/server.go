@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kerilOvs/profile_sevice/internal/api"
+	"github.com/labstack/echo/v4"
+)
+
+// Server реализует сгенерированный из openapi.yaml api.ServerInterface.
+// Если спецификация и обработчики разойдутся, проект перестанет собираться.
+type Server struct {
+	*UserHandler
+	*PhotoHandler
+	*AdminHandler
+	*HealthHandler
+}
+
+var _ api.ServerInterface = (*Server)(nil)
+
+func NewServer(userHandler *UserHandler, photoHandler *PhotoHandler, adminHandler *AdminHandler, healthHandler *HealthHandler) *Server {
+	return &Server{
+		UserHandler:   userHandler,
+		PhotoHandler:  photoHandler,
+		AdminHandler:  adminHandler,
+		HealthHandler: healthHandler,
+	}
+}
+
+// Policies - middleware доступа для каждого маршрута, ключ "METHOD /path" в формате echo.
+type Policies map[string][]echo.MiddlewareFunc
+
+// PolicyRouter навешивает политики на маршруты, которые регистрирует api.RegisterHandlers.
+// Маршрут без объявленной политики не регистрируется, а Check возвращает ошибку.
+type PolicyRouter struct {
+	e        *echo.Echo
+	policies Policies
+	used     map[string]bool
+	missing  []string
+}
+
+var _ api.EchoRouter = (*PolicyRouter)(nil)
+
+func NewPolicyRouter(e *echo.Echo, policies Policies) *PolicyRouter {
+	return &PolicyRouter{e: e, policies: policies, used: map[string]bool{}}
+}
+
+// Check проверяет, что у каждого маршрута есть политика и все политики относятся к существующим маршрутам.
+func (r *PolicyRouter) Check() error {
+	if len(r.missing) > 0 {
+		return fmt.Errorf("no access policy declared for routes: %v", r.missing)
+	}
+
+	var unused []string
+	for route := range r.policies {
+		if !r.used[route] {
+			unused = append(unused, route)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return fmt.Errorf("policies declared for routes missing from openapi.yaml: %v", unused)
+	}
+	return nil
+}
+
+func (r *PolicyRouter) add(method, path string, h echo.HandlerFunc, m []echo.MiddlewareFunc) *echo.Route {
+	route := method + " " + path
+	policy, ok := r.policies[route]
+	if !ok {
+		r.missing = append(r.missing, route)
+		return nil
+	}
+	r.used[route] = true
+
+	return r.e.Add(method, escapeColons(path), h, slices.Concat(policy, m)...)
+}
+
+// escapeColons экранирует двоеточия внутри сегмента пути (POST /users:batchGet),
+// иначе echo примет их за начало параметра. Параметры пути всегда начинают сегмент.
+func escapeColons(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == ':' && i > 0 && path[i-1] != '/' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
+
+func (r *PolicyRouter) CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(echo.CONNECT, path, h, m)
+}
+
+func (r *PolicyRouter) DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(echo.DELETE, path, h, m)
+}
+
+func (r *PolicyRouter) GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(echo.GET, path, h, m)
+}
+
+func (r *PolicyRouter) HEAD(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(echo.HEAD, path, h, m)
+}
+
+func (r *PolicyRouter) OPTIONS(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(echo.OPTIONS, path, h, m)
+}
+
+func (r *PolicyRouter) PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(echo.PATCH, path, h, m)
+}
+
+func (r *PolicyRouter) POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(echo.POST, path, h, m)
+}
+
+func (r *PolicyRouter) PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(echo.PUT, path, h, m)
+}
+
+func (r *PolicyRouter) TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(echo.TRACE, path, h, m)
+}
@@ -1,17 +1,17 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
-	"strings"
 
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
-	"github.com/kerilOvs/profile_sevice/internal/models"
+	"github.com/kerilOvs/profile_sevice/internal/api"
+	"github.com/kerilOvs/profile_sevice/internal/auth"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
 	"github.com/kerilOvs/profile_sevice/internal/service"
 	"github.com/labstack/echo/v4"
 )
 
+var errInvalidBody = errorsExt.Validation(errorsExt.CodeInvalidBody, "Invalid request body")
+
 type UserHandler struct {
 	service *service.UserService
 }
@@ -20,439 +20,206 @@ func NewUserHandler(service *service.UserService) *UserHandler {
 	return &UserHandler{service: service}
 }
 
-func (h *UserHandler) CreateUser(c echo.Context) error {
-	var req struct {
-		Id          uuid.UUID          `json:"id"`
-		Name        string             `json:"name"`
-		Surname     string             `json:"surname"`
-		AboutMyself *string            `json:"about_myself,omitempty"`
-		Gender      *models.UserGender `json:"gender,omitempty"`
+func (h *UserHandler) ListUsers(c echo.Context, params api.ListUsersParams) error {
+	search := service.UserSearch{
+		Gender:        params.Gender,
+		AgeMin:        params.AgeMin,
+		AgeMax:        params.AgeMax,
+		JungResult:    params.JungResult,
+		Tags:          deref(params.Tags),
+		AllTags:       deref(params.TagsMode) == api.All,
+		CreatedAfter:  params.CreatedAfter,
+		CreatedBefore: params.CreatedBefore,
+		IncludeHidden: deref(params.IncludeHidden),
+		Cursor:        deref(params.Cursor),
+		Limit:         deref(params.Limit),
 	}
 
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid request body"))
+	// Скрытые и забаненные профили видят только администраторы и модераторы
+	if search.IncludeHidden {
+		if principal, ok := PrincipalFrom(c); !ok || !principal.HasRole(auth.RoleAdmin, auth.RoleModerator) {
+			return errInsufficientPermissions
+		}
 	}
 
-	user, err := h.service.CreateUser(req.Id, req.Name, req.Surname, req.AboutMyself, req.Gender)
+	users, next, err := h.service.SearchUsers(c.Request().Context(), search)
 	if err != nil {
-		return errorResponseWithCode(c, err, http.StatusBadRequest)
+		return err
 	}
 
-	return c.JSON(http.StatusCreated, user)
-}
-
-func (h *UserHandler) DeleteUser(c echo.Context) error {
-	requestedID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
+	page := api.UserPage{Items: make([]api.User, len(users))}
+	for i, user := range users {
+		page.Items[i] = *user
 	}
-
-	tokenUserID, err := getJWTUserID(c.Request())
-	if err != nil {
-		return c.JSON(http.StatusUnauthorized, errorResponse("Invalid or missing JWT token"))
+	if next != "" {
+		page.NextCursor = &next
 	}
-
-	if requestedID != tokenUserID {
-		return c.JSON(http.StatusForbidden, errorResponse("You can only access your own data"))
-	}
-
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
-	}
-
-	if err := h.service.DeleteUser(id); err != nil {
-		return errorResponseWithCode(c, err, http.StatusInternalServerError)
-	}
-
-	return c.NoContent(http.StatusNoContent)
+	return c.JSON(http.StatusOK, page)
 }
 
-func (h *UserHandler) GetUserById(c echo.Context) error {
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
+func (h *UserHandler) BatchGetUsers(c echo.Context) error {
+	var req api.BatchGetUsersJSONRequestBody
+	if err := c.Bind(&req); err != nil {
+		return errInvalidBody
 	}
 
-	user, err := h.service.GetUserByID(id)
+	users, missing, err := h.service.BatchGetUsers(c.Request().Context(), req.Ids)
 	if err != nil {
-		return errorResponseWithCode(c, err, http.StatusNotFound)
+		return err
 	}
 
-	return c.JSON(http.StatusOK, user)
-}
-
-func (h *UserHandler) UpdateUserProfile(c echo.Context) error {
-	requestedID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
+	batch := api.UserBatch{Users: make([]api.UserSummary, len(users)), Missing: missing}
+	for i, user := range users {
+		batch.Users[i] = *user
 	}
+	return c.JSON(http.StatusOK, batch)
+}
 
-	tokenUserID, err := getJWTUserID(c.Request())
-	if err != nil {
-		return c.JSON(http.StatusUnauthorized, errorResponse("Invalid or missing JWT token"))
+func (h *UserHandler) CreateUser(c echo.Context, _ api.CreateUserParams) error {
+	var req api.CreateUserJSONRequestBody
+	if err := c.Bind(&req); err != nil {
+		return errInvalidBody
 	}
 
-	if requestedID != tokenUserID {
-		return c.JSON(http.StatusForbidden, errorResponse("You can only access your own data"))
+	// Пользователь может создать только свой профиль, произвольный id доступен лишь сервисам
+	if principal, ok := PrincipalFrom(c); ok && !principal.IsService() && principal.UserID != req.Id {
+		return errorsExt.Forbidden(errorsExt.CodeNotOwner, "You can only create your own profile")
 	}
 
-	id, err := uuid.Parse(c.Param("id"))
+	user, err := h.service.CreateUser(c.Request().Context(), req.Id, req.Name, req.Surname, req.AboutMyself, req.Gender)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
+		return err
 	}
 
-	var req models.UserProfileUpdate
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid request body"))
-	}
+	return c.JSON(http.StatusCreated, user)
+}
 
-	if err := h.service.UpdateUserProfile(id, req); err != nil {
-		return errorResponseWithCode(c, err, http.StatusBadRequest)
+func (h *UserHandler) DeleteUser(c echo.Context, id api.UserId, _ api.DeleteUserParams) error {
+	if err := h.service.DeleteUser(c.Request().Context(), id); err != nil {
+		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
-func (h *UserHandler) UpdateUserAbout(c echo.Context) error {
-	requestedID, err := uuid.Parse(c.Param("id"))
+func (h *UserHandler) GetUserById(c echo.Context, id api.UserId) error {
+	user, err := h.service.GetUserByID(c.Request().Context(), id)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
+		return err
 	}
 
-	tokenUserID, err := getJWTUserID(c.Request())
-	if err != nil {
-		return c.JSON(http.StatusUnauthorized, errorResponse("Invalid or missing JWT token"))
-	}
-
-	if requestedID != tokenUserID {
-		return c.JSON(http.StatusForbidden, errorResponse("You can only access your own data"))
-	}
-
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
-	}
+	return c.JSON(http.StatusOK, user)
+}
 
-	var req struct {
-		AboutMyself string `json:"about_myself"`
-	}
+func (h *UserHandler) UpdateUserProfile(c echo.Context, id api.UserId, _ api.UpdateUserProfileParams) error {
+	var req api.UpdateUserProfileJSONRequestBody
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid request body"))
+		return errInvalidBody
 	}
 
-	if err := h.service.UpdateUserAbout(id, req.AboutMyself); err != nil {
-		return errorResponseWithCode(c, err, http.StatusBadRequest)
+	if err := h.service.UpdateUserProfile(c.Request().Context(), id, req); err != nil {
+		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
-func (h *UserHandler) UpdateUserName(c echo.Context) error {
-	requestedID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
-	}
-
-	tokenUserID, err := getJWTUserID(c.Request())
-	if err != nil {
-		return c.JSON(http.StatusUnauthorized, errorResponse("Invalid or missing JWT token"))
-	}
-
-	if requestedID != tokenUserID {
-		return c.JSON(http.StatusForbidden, errorResponse("You can only access your own data"))
-	}
-
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
-	}
-
-	var req struct {
-		Name string `json:"name"`
-	}
+func (h *UserHandler) UpdateUserAbout(c echo.Context, id api.UserId, _ api.UpdateUserAboutParams) error {
+	var req api.UpdateUserAboutJSONRequestBody
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid request body"))
+		return errInvalidBody
 	}
 
-	if err := h.service.UpdateUserName(id, req.Name); err != nil {
-		return errorResponseWithCode(c, err, http.StatusBadRequest)
+	if err := h.service.UpdateUserAbout(c.Request().Context(), id, req.AboutMyself); err != nil {
+		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
-func (h *UserHandler) GetUserPhotos(c echo.Context) error {
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
-	}
-
-	photos, err := h.service.GetUserPhotos(id)
-	if err != nil {
-		return errorResponseWithCode(c, err, http.StatusInternalServerError)
-	}
-
-	return c.JSON(http.StatusOK, photos)
-}
-
-func (h *UserHandler) AddUserPhoto(c echo.Context) error {
-	requestedID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
-	}
-
-	tokenUserID, err := getJWTUserID(c.Request())
-	if err != nil {
-		return c.JSON(http.StatusUnauthorized, errorResponse("Invalid or missing JWT token"))
-	}
-
-	if requestedID != tokenUserID {
-		return c.JSON(http.StatusForbidden, errorResponse("You can only access your own data"))
-	}
-
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
-	}
-
-	var req struct {
-		URL string `json:"url"`
-	}
+func (h *UserHandler) UpdateUserName(c echo.Context, id api.UserId, _ api.UpdateUserNameParams) error {
+	var req api.UpdateUserNameJSONRequestBody
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid request body"))
+		return errInvalidBody
 	}
 
-	photo, err := h.service.AddUserPhoto(id, req.URL)
-	if err != nil {
-		return errorResponseWithCode(c, err, http.StatusBadRequest)
-	}
-
-	return c.JSON(http.StatusCreated, photo)
-}
-
-func (h *UserHandler) RemoveUserPhoto(c echo.Context) error {
-	requestedID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
-	}
-
-	tokenUserID, err := getJWTUserID(c.Request())
-	if err != nil {
-		return c.JSON(http.StatusUnauthorized, errorResponse("Invalid or missing JWT token"))
-	}
-
-	if requestedID != tokenUserID {
-		return c.JSON(http.StatusForbidden, errorResponse("You can only access your own data"))
-	}
-
-	userID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
-	}
-
-	photoID, err := uuid.Parse(c.Param("photoId"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid photo ID"))
-	}
-
-	if err := h.service.RemoveUserPhoto(userID, photoID); err != nil {
-		return errorResponseWithCode(c, err, http.StatusNotFound)
+	if err := h.service.UpdateUserName(c.Request().Context(), id, req.Name); err != nil {
+		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
-func (h *UserHandler) UpdatePrimaryPhoto(c echo.Context) error {
-	requestedID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
-	}
-
-	tokenUserID, err := getJWTUserID(c.Request())
-	if err != nil {
-		return c.JSON(http.StatusUnauthorized, errorResponse("Invalid or missing JWT token"))
-	}
-
-	if requestedID != tokenUserID {
-		return c.JSON(http.StatusForbidden, errorResponse("You can only access your own data"))
-	}
-
-	userID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
-	}
-
-	var req struct {
-		ID *uuid.UUID `json:"id,omitempty"`
-	}
+func (h *UserHandler) UpdateUserSurname(c echo.Context, id api.UserId, _ api.UpdateUserSurnameParams) error {
+	var req api.UpdateUserSurnameJSONRequestBody
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid request body"))
+		return errInvalidBody
 	}
 
-	if req.ID == nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Photo ID is required"))
-	}
-
-	if err := h.service.SetPrimaryPhoto(userID, *req.ID); err != nil {
-		return errorResponseWithCode(c, err, http.StatusNotFound)
+	if err := h.service.UpdateUserSurname(c.Request().Context(), id, req.Surname); err != nil {
+		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
-func (h *UserHandler) UpdateUserSurname(c echo.Context) error {
-	requestedID, err := uuid.Parse(c.Param("id"))
+func (h *UserHandler) GetUserPhotos(c echo.Context, id api.UserId) error {
+	photos, err := h.service.GetUserPhotos(c.Request().Context(), id)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
+		return err
 	}
 
-	tokenUserID, err := getJWTUserID(c.Request())
-	if err != nil {
-		return c.JSON(http.StatusUnauthorized, errorResponse("Invalid or missing JWT token"))
-	}
+	return c.JSON(http.StatusOK, photos)
+}
 
-	if requestedID != tokenUserID {
-		return c.JSON(http.StatusForbidden, errorResponse("You can only access your own data"))
+func (h *UserHandler) RemoveUserPhoto(c echo.Context, id api.UserId, photoID api.PhotoId, _ api.RemoveUserPhotoParams) error {
+	if err := h.service.RemoveUserPhoto(c.Request().Context(), id, photoID); err != nil {
+		return err
 	}
 
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
-	}
+	return c.NoContent(http.StatusNoContent)
+}
 
-	var req struct {
-		Surname string `json:"surname"`
-	}
+func (h *UserHandler) UpdatePrimaryPhoto(c echo.Context, id api.UserId, _ api.UpdatePrimaryPhotoParams) error {
+	var req api.UpdatePrimaryPhotoJSONRequestBody
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid request body"))
+		return errInvalidBody
 	}
 
-	if err := h.service.UpdateUserSurname(id, req.Surname); err != nil {
-		return errorResponseWithCode(c, err, http.StatusBadRequest)
+	if err := h.service.SetPrimaryPhoto(c.Request().Context(), id, req.Id); err != nil {
+		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
-func (h *UserHandler) AddUserTag(c echo.Context) error {
-	requestedID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
-	}
-
-	tokenUserID, err := getJWTUserID(c.Request())
-	if err != nil {
-		return c.JSON(http.StatusUnauthorized, errorResponse("Invalid or missing JWT token"))
-	}
-
-	if requestedID != tokenUserID {
-		return c.JSON(http.StatusForbidden, errorResponse("You can only access your own data"))
-	}
-
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
-	}
-
-	var req struct {
-		Tag string `json:"tag"`
-	}
+func (h *UserHandler) AddUserTag(c echo.Context, id api.UserId, _ api.AddUserTagParams) error {
+	var req api.AddUserTagJSONRequestBody
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid request body"))
+		return errInvalidBody
 	}
 
-	tag, err := h.service.AddUserTag(id, req.Tag)
+	tag, err := h.service.AddUserTag(c.Request().Context(), id, req.Tag, req.Category)
 	if err != nil {
-		return errorResponseWithCode(c, err, http.StatusBadRequest)
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, tag)
 }
 
-func (h *UserHandler) GetUserTags(c echo.Context) error {
-
-	id, err := uuid.Parse(c.Param("id"))
+func (h *UserHandler) GetUserTags(c echo.Context, id api.UserId) error {
+	tags, err := h.service.GetUserTags(c.Request().Context(), id)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
-	}
-
-	tags, err := h.service.GetUserTags(id)
-	if err != nil {
-		return errorResponseWithCode(c, err, http.StatusInternalServerError)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, tags)
 }
 
-func (h *UserHandler) RemoveUserTag(c echo.Context) error {
-	requestedID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
-	}
-
-	tokenUserID, err := getJWTUserID(c.Request())
-	if err != nil {
-		return c.JSON(http.StatusUnauthorized, errorResponse("Invalid or missing JWT token"))
-	}
-
-	if requestedID != tokenUserID {
-		return c.JSON(http.StatusForbidden, errorResponse("You can only access your own data"))
-	}
-
-	userID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid user ID"))
-	}
-
-	tagID, err := uuid.Parse(c.Param("tagId"))
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse("Invalid tag ID"))
-	}
-
-	if err := h.service.RemoveUserTag(userID, tagID); err != nil {
-		return errorResponseWithCode(c, err, http.StatusNotFound)
+func (h *UserHandler) RemoveUserTag(c echo.Context, id api.UserId, tagID api.TagId, _ api.RemoveUserTagParams) error {
+	if err := h.service.RemoveUserTag(c.Request().Context(), id, tagID); err != nil {
+		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
-
-func (h *UserHandler) Healthy(c echo.Context) error {
-	return nil
-}
-
-func errorResponse(msg string) map[string]interface{} {
-	return map[string]interface{}{"error": msg}
-}
-
-func errorResponseWithCode(c echo.Context, err error, code int) error {
-	return c.JSON(code, errorResponse(err.Error()))
-}
-
-func getJWTUserID(r *http.Request) (uuid.UUID, error) {
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		return uuid.UUID{}, fmt.Errorf("no authorization header provided")
-	}
-
-	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-
-	token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
-	if err != nil {
-		return uuid.UUID{}, fmt.Errorf("failed to parse jwt: %w", err)
-	}
-
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return uuid.UUID{}, fmt.Errorf("failed to parse jwt claims")
-	}
-
-	sub, err := claims.GetSubject()
-	if err != nil {
-		return uuid.UUID{}, fmt.Errorf("failed to get subject from jwt: %w", err)
-	}
-
-	id, err := uuid.Parse(sub)
-
-	return id, err
-}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/api"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/labstack/echo/v4"
+)
+
+func init() {
+	// По умолчанию kin-openapi формат uuid не проверяет
+	openapi3.DefineStringFormatValidator("uuid", openapi3.NewCallbackValidator(func(value string) error {
+		if len(value) != 36 {
+			return errors.New("must be a valid uuid")
+		}
+		if _, err := uuid.Parse(value); err != nil {
+			return errors.New("must be a valid uuid")
+		}
+		return nil
+	}))
+
+	// Файлы внутри multipart/form-data проверяем только на наличие
+	openapi3filter.RegisterBodyDecoder("image/jpeg", openapi3filter.FileBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("image/jpg", openapi3filter.FileBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("image/png", openapi3filter.FileBodyDecoder)
+}
+
+// OpenAPIValidator проверяет запросы (и в dev режиме ответы) по openapi.yaml
+// до того, как они попадут в обработчики.
+type OpenAPIValidator struct {
+	router            routers.Router
+	validateResponses bool
+	log               *slog.Logger
+}
+
+func NewOpenAPIValidator(validateResponses bool, log *slog.Logger) (*OpenAPIValidator, error) {
+	spec, err := api.GetSwagger()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load openapi spec: %w", err)
+	}
+
+	// Сервер из спецификации не совпадает с адресом, на котором нас реально вызывают
+	spec.Servers = nil
+
+	router, err := legacy.NewRouter(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build openapi router: %w", err)
+	}
+
+	return &OpenAPIValidator{
+		router:            router,
+		validateResponses: validateResponses,
+		log:               log.WithGroup("openapi"),
+	}, nil
+}
+
+func (v *OpenAPIValidator) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			route, pathParams, err := v.router.FindRoute(req)
+			if err != nil {
+				// Неизвестные маршруты и методы отдаем echo, он вернет 404/405
+				return next(c)
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: pathParams,
+				Route:      route,
+				Options: &openapi3filter.Options{
+					MultiError:         true,
+					AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+				},
+			}
+
+			if err := openapi3filter.ValidateRequest(req.Context(), input); err != nil {
+				return errorsExt.Validation(errorsExt.CodeRequestValidation, "Request validation failed", fieldErrors(err)...)
+			}
+
+			if !v.validateResponses {
+				return next(c)
+			}
+
+			return v.validateResponse(c, next, input)
+		}
+	}
+}
+
+func (v *OpenAPIValidator) validateResponse(c echo.Context, next echo.HandlerFunc, input *openapi3filter.RequestValidationInput) error {
+	original := c.Response().Writer
+	buf := &bufferedWriter{header: original.Header()}
+	c.Response().Writer = buf
+
+	if err := next(c); err != nil {
+		// Ошибку обработает echo, но уже в настоящий writer
+		c.Response().Writer = original
+		c.Response().Committed = false
+		return err
+	}
+
+	c.Response().Writer = original
+
+	status := buf.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	err := openapi3filter.ValidateResponse(c.Request().Context(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 status,
+		Header:                 original.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(buf.body.Bytes())),
+		Options:                &openapi3filter.Options{MultiError: true},
+	})
+	if err != nil {
+		fields := fieldErrors(err)
+		v.log.ErrorContext(c.Request().Context(), "response does not match openapi spec",
+			slog.String("method", c.Request().Method),
+			slog.String("path", c.Request().URL.Path),
+			slog.Int("status", status),
+			slog.Any("fields", fields),
+		)
+
+		original.Header().Del(echo.HeaderContentLength)
+		c.Response().Committed = false
+		return &errorsExt.Error{
+			Kind:    errorsExt.ErrInternal,
+			Code:    errorsExt.CodeResponseValidation,
+			Message: "Response does not match openapi.yaml",
+			Fields:  fields,
+		}
+	}
+
+	original.WriteHeader(status)
+	_, err = original.Write(buf.body.Bytes())
+	return err
+}
+
+// fieldErrors раскладывает ошибку kin-openapi на отдельные поля.
+func fieldErrors(err error) []errorsExt.FieldError {
+	var res []errorsExt.FieldError
+
+	var walk func(in, field string, err error)
+	walk = func(in, field string, err error) {
+		switch e := err.(type) {
+		case openapi3.MultiError:
+			for _, item := range e {
+				walk(in, field, item)
+			}
+		case *openapi3filter.RequestError:
+			in, field = "body", ""
+			if e.Parameter != nil {
+				in, field = e.Parameter.In, e.Parameter.Name
+			}
+			if e.Err == nil {
+				res = append(res, errorsExt.FieldError{Field: field, In: in, Message: e.Reason})
+				return
+			}
+			walk(in, field, e.Err)
+		case *openapi3filter.ResponseError:
+			if e.Err == nil {
+				res = append(res, errorsExt.FieldError{Field: field, In: "body", Message: e.Reason})
+				return
+			}
+			walk("body", field, e.Err)
+		case *openapi3.SchemaError:
+			path := strings.Join(e.JSONPointer(), ".")
+			if field != "" && path != "" {
+				path = field + "." + path
+			} else if path == "" {
+				path = field
+			}
+			res = append(res, errorsExt.FieldError{Field: path, In: in, Message: e.Reason})
+		default:
+			if inner := errors.Unwrap(err); inner != nil {
+				walk(in, field, inner)
+				return
+			}
+			res = append(res, errorsExt.FieldError{Field: field, In: in, Message: err.Error()})
+		}
+	}
+
+	walk("body", "", err)
+	return res
+}
+
+// bufferedWriter копит ответ, чтобы проверить его до отправки клиенту.
+type bufferedWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
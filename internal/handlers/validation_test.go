@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kerilOvs/profile_sevice/internal/api"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/labstack/echo/v4"
+)
+
+const validUserID = "7d7cf2a4-6c3b-4f43-9a43-3a1e5c6b2f10"
+
+// newValidatedEcho вешает валидатор на маршруты из openapi.yaml, ответы отдает handler.
+func newValidatedEcho(t *testing.T, validateResponses bool, handler echo.HandlerFunc) *echo.Echo {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	validator, err := NewOpenAPIValidator(validateResponses, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler(log)
+	e.Use(validator.Middleware())
+	e.GET("/users/:id", handler)
+	e.PATCH("/users/:id/name", handler)
+	return e
+}
+
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) api.Problem {
+	t.Helper()
+
+	if ct := rec.Header().Get(echo.HeaderContentType); ct != problemContentType {
+		t.Fatalf("content type = %q, want %q: %s", ct, problemContentType, rec.Body)
+	}
+	var problem api.Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("invalid problem body %s: %v", rec.Body, err)
+	}
+	return problem
+}
+
+func TestValidatorRejectsInvalidRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		field  api.FieldError
+	}{
+		{"path parameter", http.MethodGet, "/users/not-a-uuid", "", api.FieldError{Field: "id", In: "path"}},
+		{"missing body field", http.MethodPatch, "/users/" + validUserID + "/name", `{}`, api.FieldError{In: "body"}},
+		{"body field too long", http.MethodPatch, "/users/" + validUserID + "/name", `{"name":"` + strings.Repeat("a", 101) + `"}`, api.FieldError{Field: "name", In: "body"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			e := newValidatedEcho(t, false, func(c echo.Context) error {
+				called = true
+				return c.NoContent(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest || called {
+				t.Fatalf("status %d, handler called %v, want 400 before the handler: %s", rec.Code, called, rec.Body)
+			}
+			problem := decodeProblem(t, rec)
+			if problem.Code != errorsExt.CodeRequestValidation || problem.Fields == nil || len(*problem.Fields) == 0 {
+				t.Fatalf("problem = %+v", problem)
+			}
+			got := (*problem.Fields)[0]
+			if got.In != tt.field.In || (tt.field.Field != "" && got.Field != tt.field.Field) {
+				t.Fatalf("field = %+v, want %+v", got, tt.field)
+			}
+		})
+	}
+}
+
+func TestValidatorChecksResponses(t *testing.T) {
+	offSpec := func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]any{"id": c.Param("id"), "gender": "UNKNOWN"})
+	}
+	valid := func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]any{"id": c.Param("id"), "name": "Ivan", "surname": "Petrov", "gender": "MALE", "created_at": "2025-01-01T12:00:00Z"})
+	}
+
+	tests := []struct {
+		name              string
+		validateResponses bool
+		handler           echo.HandlerFunc
+		wantCode          int
+	}{
+		{"off-spec response is replaced", true, offSpec, http.StatusInternalServerError},
+		{"valid response passes", true, valid, http.StatusOK},
+		{"off-spec response without validation", false, offSpec, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newValidatedEcho(t, tt.validateResponses, tt.handler)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/"+validUserID, nil))
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.wantCode, rec.Body)
+			}
+			if tt.wantCode == http.StatusOK {
+				if !strings.Contains(rec.Body.String(), validUserID) {
+					t.Fatalf("body = %s, want the handler response", rec.Body)
+				}
+				return
+			}
+
+			problem := decodeProblem(t, rec)
+			if problem.Code != errorsExt.CodeResponseValidation || problem.Fields == nil {
+				t.Fatalf("problem = %+v", problem)
+			}
+			if strings.Contains(rec.Body.String(), `"gender":"UNKNOWN"`) {
+				t.Fatalf("off-spec body leaked to the client: %s", rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS outbox_messages;
+DROP TABLE IF EXISTS idempotency_records;
+DROP TABLE IF EXISTS audit_records;
+DROP TABLE IF EXISTS user_tags;
+DROP TABLE IF EXISTS user_photos;
+DROP TABLE IF EXISTS users;
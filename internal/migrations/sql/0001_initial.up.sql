@@ -0,0 +1,78 @@
+-- Схема в том виде, в каком ее создавал gorm AutoMigrate. На базах, созданных
+-- AutoMigrate, таблицы уже есть, поэтому все с IF NOT EXISTS, а колонки, которых
+-- не было в исходной схеме, добавляются отдельно.
+CREATE TABLE IF NOT EXISTS users (
+    id                text PRIMARY KEY,
+    name              text,
+    surname           text,
+    about_myself      text,
+    gender            text,
+    birth_date        timestamptz,
+    jung_result       text,
+    jung_last_attempt timestamptz,
+    primary_photo     text,
+    created_at        timestamptz,
+    hidden            boolean,
+    banned_at         timestamptz,
+    ban_reason        text,
+    tags_version      bigint NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS user_photos (
+    id      text PRIMARY KEY,
+    user_id text,
+    url     text,
+    CONSTRAINT fk_users_photos FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE TABLE IF NOT EXISTS user_tags (
+    id       text PRIMARY KEY,
+    user_id  text,
+    value    text,
+    category text,
+    CONSTRAINT fk_users_tags FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS hidden boolean,
+    ADD COLUMN IF NOT EXISTS banned_at timestamptz,
+    ADD COLUMN IF NOT EXISTS ban_reason text,
+    ADD COLUMN IF NOT EXISTS tags_version bigint NOT NULL DEFAULT 0;
+ALTER TABLE user_tags ADD COLUMN IF NOT EXISTS category text;
+
+CREATE TABLE IF NOT EXISTS audit_records (
+    id             text PRIMARY KEY,
+    actor_id       text,
+    action         text,
+    target_user_id text,
+    details        text,
+    created_at     timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS idempotency_records (
+    owner        text,
+    key          text,
+    route        text,
+    request_hash text,
+    status_code  bigint,
+    content_type text,
+    body         bytea,
+    created_at   timestamptz,
+    completed_at timestamptz,
+    PRIMARY KEY (owner, key, route)
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_records_created_at ON idempotency_records (created_at);
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id              text PRIMARY KEY,
+    topic           text,
+    payload         bytea,
+    created_at      timestamptz,
+    next_attempt_at timestamptz,
+    attempts        bigint,
+    last_error      text,
+    sent_at         timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_created_at ON outbox_messages (created_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_next_attempt_at ON outbox_messages (next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_sent_at ON outbox_messages (sent_at);
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_audit_records_target_user_id;
+DROP INDEX IF EXISTS idx_user_tags_user_id;
+DROP INDEX IF EXISTS idx_user_photos_user_id;
+
+ALTER TABLE user_tags DROP CONSTRAINT IF EXISTS fk_user_tags_user;
+ALTER TABLE user_tags
+    ADD CONSTRAINT fk_users_tags FOREIGN KEY (user_id) REFERENCES users (id);
+
+ALTER TABLE user_photos DROP CONSTRAINT IF EXISTS fk_user_photos_user;
+ALTER TABLE user_photos
+    ADD CONSTRAINT fk_users_photos FOREIGN KEY (user_id) REFERENCES users (id);
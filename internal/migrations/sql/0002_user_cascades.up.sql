@@ -0,0 +1,16 @@
+-- Фото и теги удаляются вместе с пользователем. Сироты, оставшиеся от удалений
+-- без внешнего ключа, чистим до создания ограничений.
+DELETE FROM user_photos p WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = p.user_id);
+DELETE FROM user_tags t WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = t.user_id);
+
+ALTER TABLE user_photos DROP CONSTRAINT IF EXISTS fk_users_photos;
+ALTER TABLE user_photos
+    ADD CONSTRAINT fk_user_photos_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE user_tags DROP CONSTRAINT IF EXISTS fk_users_tags;
+ALTER TABLE user_tags
+    ADD CONSTRAINT fk_user_tags_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_user_photos_user_id ON user_photos (user_id);
+CREATE INDEX IF NOT EXISTS idx_user_tags_user_id ON user_tags (user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_records_target_user_id ON audit_records (target_user_id, created_at DESC);
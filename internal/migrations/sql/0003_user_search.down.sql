@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_user_tags_value_user_id;
+DROP INDEX IF EXISTS idx_users_jung_result;
+DROP INDEX IF EXISTS idx_users_birth_date;
+DROP INDEX IF EXISTS idx_users_visible_created_at_id;
+DROP INDEX IF EXISTS idx_users_created_at_id;
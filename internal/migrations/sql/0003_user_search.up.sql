@@ -0,0 +1,8 @@
+-- Индексы для GET /users: выдача по (created_at, id) от новых к старым и фильтры.
+-- Обычная выдача не показывает скрытых и забаненных, для нее отдельный частичный индекс.
+CREATE INDEX IF NOT EXISTS idx_users_created_at_id ON users (created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_users_visible_created_at_id ON users (created_at DESC, id DESC)
+    WHERE hidden IS NOT TRUE AND banned_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_birth_date ON users (birth_date);
+CREATE INDEX IF NOT EXISTS idx_users_jung_result ON users (jung_result);
+CREATE INDEX IF NOT EXISTS idx_user_tags_value_user_id ON user_tags (value, user_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_users_search_vector;
+DROP TRIGGER IF EXISTS user_tags_search_vector ON user_tags;
+DROP TRIGGER IF EXISTS users_search_vector ON users;
+DROP FUNCTION IF EXISTS user_tags_search_vector_trigger();
+DROP FUNCTION IF EXISTS users_search_vector_trigger();
+DROP FUNCTION IF EXISTS user_search_vector(text, text, text, text);
+ALTER TABLE users DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,52 @@
+-- Полнотекстовый поиск по имени, фамилии, "о себе" и тегам. Пользователи пишут
+-- и по-русски, и по-английски, поэтому документ строится по обоим словарям.
+-- Веса: A - имя и фамилия, B - теги, C - "о себе".
+ALTER TABLE users ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+-- Аргументы: id, name, surname, about_myself
+CREATE OR REPLACE FUNCTION user_search_vector(text, text, text, text)
+RETURNS tsvector LANGUAGE sql STABLE AS $$
+    WITH doc AS (
+        SELECT concat_ws(' ', $2, $3) AS names,
+               coalesce($4, '') AS about,
+               coalesce((SELECT string_agg(t.value, ' ') FROM user_tags t WHERE t.user_id = $1), '') AS tags
+    )
+    SELECT setweight(to_tsvector('russian', names), 'A') || setweight(to_tsvector('english', names), 'A') ||
+           setweight(to_tsvector('russian', tags), 'B') || setweight(to_tsvector('english', tags), 'B') ||
+           setweight(to_tsvector('russian', about), 'C') || setweight(to_tsvector('english', about), 'C')
+    FROM doc
+$$;
+
+CREATE OR REPLACE FUNCTION users_search_vector_trigger() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.search_vector := user_search_vector(NEW.id, NEW.name, NEW.surname, NEW.about_myself);
+    RETURN NEW;
+END
+$$;
+
+CREATE TRIGGER users_search_vector
+    BEFORE INSERT OR UPDATE OF name, surname, about_myself ON users
+    FOR EACH ROW EXECUTE FUNCTION users_search_vector_trigger();
+
+-- Теги лежат в отдельной таблице: при их изменении пересчитываем документ владельца
+CREATE OR REPLACE FUNCTION user_tags_search_vector_trigger() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE users u SET search_vector = user_search_vector(u.id, u.name, u.surname, u.about_myself)
+        WHERE u.id = OLD.user_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE users u SET search_vector = user_search_vector(u.id, u.name, u.surname, u.about_myself)
+        WHERE u.id = NEW.user_id;
+    END IF;
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER user_tags_search_vector
+    AFTER INSERT OR UPDATE OR DELETE ON user_tags
+    FOR EACH ROW EXECUTE FUNCTION user_tags_search_vector_trigger();
+
+UPDATE users SET search_vector = user_search_vector(id, name, surname, about_myself);
+
+CREATE INDEX IF NOT EXISTS idx_users_search_vector ON users USING gin (search_vector);
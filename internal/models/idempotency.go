@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// IdempotencyRecord хранит результат запроса с заголовком Idempotency-Key.
+// Пока CompletedAt пустой, запрос считается выполняющимся.
+type IdempotencyRecord struct {
+	Owner       string `gorm:"primaryKey"`
+	Key         string `gorm:"primaryKey"`
+	Route       string `gorm:"primaryKey"`
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time `gorm:"index"`
+	CompletedAt *time.Time
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxMessage - событие, записанное в одной транзакции с изменением данных.
+// Relay отправляет его в брокер и проставляет SentAt.
+type OutboxMessage struct {
+	ID            uuid.UUID `gorm:"primaryKey"`
+	Topic         string
+	Payload       []byte
+	CreatedAt     time.Time `gorm:"index"`
+	NextAttemptAt time.Time `gorm:"index"`
+	Attempts      int
+	LastError     *string
+	SentAt        *time.Time `gorm:"index"`
+}
@@ -24,6 +24,10 @@ type User struct {
 	JungLastAttempt *time.Time  `json:"jung_last_attempt,omitempty"`
 	PrimaryPhoto    *string     `json:"primary_photo,omitempty"`
 	CreatedAt       time.Time   `json:"created_at"`
+	Hidden          bool        `json:"hidden,omitempty"`
+	BannedAt        *time.Time  `json:"banned_at,omitempty"`
+	BanReason       *string     `json:"ban_reason,omitempty"`
+	TagsVersion     int64       `gorm:"not null;default:0" json:"-"` // растет при каждом изменении набора тегов
 	Photos          []UserPhoto `gorm:"foreignKey:UserID" json:"photos,omitempty"`
 	Tags            []UserTag   `gorm:"foreignKey:UserID" json:"tags,omitempty"`
 }
@@ -35,9 +39,10 @@ type UserPhoto struct {
 }
 
 type UserTag struct {
-	ID     uuid.UUID `json:"id"`
-	UserID uuid.UUID `json:"user_id"`
-	Value  string    `json:"value"`
+	ID       uuid.UUID `json:"id"`
+	UserID   uuid.UUID `json:"user_id"`
+	Value    string    `json:"value"`
+	Category *string   `json:"category,omitempty"`
 }
 
 type UserProfileUpdate struct {
@@ -49,3 +54,33 @@ type UserProfileUpdate struct {
 	JungResult      *string     `json:"jung_result,omitempty"`
 	JungLastAttempt *time.Time  `json:"jung_last_attempt,omitempty"` // Новое поле
 }
+
+// UserSummary - короткий профиль для лент и карточек, без фото кроме главного.
+type UserSummary struct {
+	ID           uuid.UUID   `json:"id"`
+	Name         string      `json:"name"`
+	Surname      string      `json:"surname"`
+	Gender       *UserGender `json:"gender,omitempty"`
+	BirthDate    *time.Time  `json:"birth_date,omitempty"`
+	JungResult   *string     `json:"jung_result,omitempty"`
+	PrimaryPhoto *string     `json:"primary_photo,omitempty"`
+	Tags         []UserTag   `json:"tags"`
+}
+
+// UserSearchHit - результат полнотекстового поиска. Snippet - экранированный HTML,
+// совпадения обернуты в <b></b>.
+type UserSearchHit struct {
+	User    User    `json:"user"`
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// AuditRecord - запись о действии администратора или модератора.
+type AuditRecord struct {
+	ID           uuid.UUID `json:"id"`
+	ActorID      uuid.UUID `json:"actor_id"`
+	Action       string    `json:"action"`
+	TargetUserID uuid.UUID `json:"target_user_id"`
+	Details      *string   `json:"details,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
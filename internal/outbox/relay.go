@@ -0,0 +1,165 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/config"
+	"github.com/kerilOvs/profile_sevice/internal/events"
+	"github.com/kerilOvs/profile_sevice/internal/storage"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+	defaultMaxBackoff   = 5 * time.Minute
+	defaultRetention    = 7 * 24 * time.Hour
+
+	baseBackoff     = time.Second
+	claimLease      = time.Minute
+	cleanupInterval = time.Hour
+	// shutdownDrain - сколько relay после остановки досылает уже записанные события
+	shutdownDrain = 5 * time.Second
+)
+
+// Relay периодически забирает неотправленные сообщения из outbox и публикует их.
+// Неудачные попытки повторяются с экспоненциальной задержкой, пока брокер не примет сообщение.
+type Relay struct {
+	storage      storage.OutboxStorage
+	publisher    events.EventPublisher
+	pollInterval time.Duration
+	batchSize    int
+	maxBackoff   time.Duration
+	retention    time.Duration
+	log          *slog.Logger
+}
+
+func NewRelay(storage storage.OutboxStorage, publisher events.EventPublisher, cfg config.OutboxConfig, log *slog.Logger) *Relay {
+	r := &Relay{
+		storage:      storage,
+		publisher:    publisher,
+		pollInterval: cfg.PollInterval,
+		batchSize:    cfg.BatchSize,
+		maxBackoff:   cfg.MaxBackoff,
+		retention:    cfg.Retention,
+		log:          log.WithGroup("outbox"),
+	}
+	if r.pollInterval <= 0 {
+		r.pollInterval = defaultPollInterval
+	}
+	if r.batchSize <= 0 {
+		r.batchSize = defaultBatchSize
+	}
+	if r.maxBackoff <= 0 {
+		r.maxBackoff = defaultMaxBackoff
+	}
+	if r.retention <= 0 {
+		r.retention = defaultRetention
+	}
+	return r
+}
+
+// Run работает, пока не отменен ctx. После отмены отправляет то, что уже лежит в outbox
+// (не дольше shutdownDrain), чтобы события последних запросов не ждали следующего запуска.
+func (r *Relay) Run(ctx context.Context) {
+	poll := time.NewTicker(r.pollInterval)
+	defer poll.Stop()
+	cleanup := time.NewTicker(cleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			drainCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownDrain)
+			defer cancel()
+			r.drain(drainCtx)
+			return
+		case <-poll.C:
+			r.drain(ctx)
+		case <-cleanup.C:
+			if err := r.storage.DeleteSentBefore(ctx, time.Now().Add(-r.retention)); err != nil {
+				r.log.ErrorContext(ctx, "failed to clean up sent outbox messages", slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// drain отправляет пачки, пока outbox не опустеет или брокер не начнет отказывать.
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		sent, err := r.processBatch(ctx)
+		if err != nil {
+			r.log.WarnContext(ctx, "failed to relay outbox messages", slog.Any("error", err))
+			return
+		}
+		if sent < r.batchSize {
+			return
+		}
+	}
+}
+
+func (r *Relay) processBatch(ctx context.Context) (int, error) {
+	messages, err := r.storage.ClaimPending(ctx, r.batchSize, claimLease)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, msg := range messages {
+		var event events.Envelope
+		if err := json.Unmarshal(msg.Payload, &event); err != nil {
+			// Повтор тут не поможет, поэтому не держим из-за него остальные сообщения
+			r.log.ErrorContext(ctx, "skipping malformed outbox message", slog.String("id", msg.ID.String()), slog.Any("error", err))
+			if markErr := r.storage.MarkFailed(ctx, msg.ID, err.Error(), time.Now().Add(r.maxBackoff)); markErr != nil {
+				return i, markErr
+			}
+			continue
+		}
+
+		if err := r.publisher.Publish(ctx, event); err != nil {
+			next := time.Now().Add(r.backoff(msg.Attempts))
+			if markErr := r.storage.MarkFailed(ctx, msg.ID, err.Error(), next); markErr != nil {
+				r.log.ErrorContext(ctx, "failed to mark outbox message as failed", slog.Any("error", markErr))
+			}
+
+			// Оставшиеся сообщения пачки откладываем вместе с упавшим, чтобы они не ушли раньше него.
+			// Это держит порядок только внутри пачки: сообщения, записанные позже, и пачки других
+			// экземпляров relay могут уйти до повтора, поэтому порядок по пользователю не гарантирован
+			rest := make([]uuid.UUID, 0, len(messages)-i-1)
+			for _, m := range messages[i+1:] {
+				rest = append(rest, m.ID)
+			}
+			if rescheduleErr := r.storage.Reschedule(ctx, rest, next); rescheduleErr != nil {
+				r.log.ErrorContext(ctx, "failed to reschedule outbox messages", slog.Any("error", rescheduleErr))
+			}
+
+			r.log.WarnContext(ctx, "outbox message will be retried",
+				slog.String("id", msg.ID.String()),
+				slog.String("topic", msg.Topic),
+				slog.Int("attempts", msg.Attempts+1),
+				slog.Time("next_attempt_at", next),
+			)
+			return i, err
+		}
+
+		if err := r.storage.MarkSent(ctx, msg.ID); err != nil {
+			// Сообщение уйдет повторно после истечения lease, получатели должны быть идемпотентны
+			return i, err
+		}
+	}
+
+	return len(messages), nil
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	if attempts > 30 {
+		return r.maxBackoff
+	}
+	d := baseBackoff << attempts
+	if d <= 0 || d > r.maxBackoff {
+		return r.maxBackoff
+	}
+	return d
+}
@@ -0,0 +1,65 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/config"
+	"github.com/kerilOvs/profile_sevice/internal/events"
+	"github.com/kerilOvs/profile_sevice/internal/models"
+	"github.com/kerilOvs/profile_sevice/internal/storage/memory"
+)
+
+func addMessage(t *testing.T, users *memory.UserMemoryStorage, eventType string) events.Envelope {
+	t.Helper()
+
+	event, err := events.New(context.Background(), eventType, events.UserDeleted{UserID: uuid.New()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := &models.OutboxMessage{ID: uuid.New(), Topic: eventType, Payload: payload, CreatedAt: time.Now()}
+	if err := users.AddOutboxMessage(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	return event
+}
+
+func TestRunDrainsOutboxOnShutdown(t *testing.T) {
+	users := memory.NewUserMemoryStorage()
+	recorder := events.NewRecorder(0, nil)
+	// Интервал опроса больше времени теста: отправить события relay может только при остановке
+	relay := NewRelay(memory.NewOutboxMemoryStorage(users), recorder, config.OutboxConfig{PollInterval: time.Hour},
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay.Run(ctx)
+	}()
+
+	want := addMessage(t, users, events.TypeUserDeleted)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(shutdownDrain + time.Second):
+		t.Fatal("relay did not stop")
+	}
+
+	published := recorder.Events()
+	if len(published) != 1 || published[0].ID != want.ID {
+		t.Fatalf("published = %+v, want the pending event", published)
+	}
+	if msg := users.OutboxMessages()[0]; msg.SentAt == nil {
+		t.Fatalf("outbox message not marked as sent: %+v", msg)
+	}
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/kerilOvs/profile_sevice/internal/events"
+)
+
+// AccountEvents создает и удаляет профили по событиям сервиса авторизации.
+// Доставка at-least-once, поэтому повторы (профиль уже есть или уже удален) не ошибка.
+type AccountEvents struct {
+	users *UserService
+	log   *slog.Logger
+}
+
+func NewAccountEvents(users *UserService, log *slog.Logger) *AccountEvents {
+	return &AccountEvents{users: users, log: log}
+}
+
+// Handle возвращает Validation/Unprocessable для сообщений, которые не обработать повтором.
+func (h *AccountEvents) Handle(ctx context.Context, event events.Envelope) error {
+	switch event.Type {
+	case events.TypeAccountRegistered:
+		var data events.AccountRegistered
+		if err := decodeEvent(event, &data); err != nil {
+			return err
+		}
+		if err := requireUserID(event.Type, data.UserID); err != nil {
+			return err
+		}
+
+		_, err := h.users.CreateUser(ctx, data.UserID, data.Name, data.Surname, nil, data.Gender)
+		if errors.Is(err, errorsExt.ErrConflict) {
+			h.log.Debug("profile already exists", slog.String("user_id", data.UserID.String()))
+			return nil
+		}
+		return err
+
+	case events.TypeAccountDeleted:
+		var data events.AccountDeleted
+		if err := decodeEvent(event, &data); err != nil {
+			return err
+		}
+		if err := requireUserID(event.Type, data.UserID); err != nil {
+			return err
+		}
+
+		err := h.users.DeleteUser(ctx, data.UserID)
+		if errors.Is(err, errorsExt.ErrNotFound) {
+			h.log.Debug("profile already deleted", slog.String("user_id", data.UserID.String()))
+			return nil
+		}
+		return err
+	}
+
+	return errorsExt.Unprocessable(errorsExt.CodeUnknownEvent, "unknown event type "+event.Type)
+}
+
+func decodeEvent(event events.Envelope, data any) error {
+	if err := json.Unmarshal(event.Data, data); err != nil {
+		return errorsExt.Validation(errorsExt.CodeInvalidEvent, "invalid "+event.Type+" data: "+err.Error())
+	}
+	return nil
+}
+
+func requireUserID(eventType string, id uuid.UUID) error {
+	if id == uuid.Nil {
+		return errorsExt.Validation(errorsExt.CodeInvalidEvent, eventType+" without user_id",
+			errorsExt.FieldError{Field: "user_id", In: "body", Message: "is required"})
+	}
+	return nil
+}
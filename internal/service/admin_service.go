@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/kerilOvs/profile_sevice/internal/models"
+	"github.com/kerilOvs/profile_sevice/internal/storage"
+)
+
+// Действия администраторов и модераторов, попадающие в журнал аудита
+const (
+	AuditUpdateUser  = "user.update"
+	AuditDeleteUser  = "user.delete"
+	AuditHideUser    = "user.hide"
+	AuditUnhideUser  = "user.unhide"
+	AuditBanUser     = "user.ban"
+	AuditUnbanUser   = "user.unban"
+	AuditRemovePhoto = "photo.remove"
+	AuditRemoveTag   = "tag.remove"
+)
+
+const maxListLimit = 100
+
+func (s *UserService) ListUsers(ctx context.Context, offset, limit int) ([]*models.User, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > maxListLimit {
+		limit = maxListLimit
+	}
+	return s.storage.ListUsers(ctx, offset, limit)
+}
+
+// SearchUsersFullText - полнотекстовый поиск для поддержки по имени, фамилии, "о себе" и тегам,
+// лучшие совпадения первыми. Видит в том числе скрытых и забаненных.
+func (s *UserService) SearchUsersFullText(ctx context.Context, query string, offset, limit int) ([]*models.UserSearchHit, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errorsExt.Validation(errorsExt.CodeRequestValidation, "search query is empty",
+			errorsExt.FieldError{Field: "q", In: "query", Message: "is required"})
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	hits, err := s.storage.SearchUsersFullText(ctx, query, offset, limit)
+	if hits == nil && err == nil {
+		hits = []*models.UserSearchHit{}
+	}
+	return hits, err
+}
+
+// AdminGetUser возвращает профиль, включая скрытые и забаненные.
+func (s *UserService) AdminGetUser(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	return s.getUserWithRelations(ctx, id)
+}
+
+func (s *UserService) AdminUpdateUser(ctx context.Context, actorID, id uuid.UUID, updates models.UserProfileUpdate) error {
+	return s.storage.WithTx(ctx, func(tx storage.UserStorage) error {
+		if err := ensureUserExists(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := s.updateProfile(ctx, tx, id, updates); err != nil {
+			return err
+		}
+		return audit(ctx, tx, actorID, AuditUpdateUser, id, updates)
+	})
+}
+
+func (s *UserService) AdminDeleteUser(ctx context.Context, actorID, id uuid.UUID) error {
+	return s.storage.WithTx(ctx, func(tx storage.UserStorage) error {
+		if err := ensureUserExists(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := deleteUser(ctx, tx, id); err != nil {
+			return err
+		}
+		return audit(ctx, tx, actorID, AuditDeleteUser, id, nil)
+	})
+}
+
+func (s *UserService) SetUserHidden(ctx context.Context, actorID, id uuid.UUID, hidden bool) error {
+	action := AuditHideUser
+	if !hidden {
+		action = AuditUnhideUser
+	}
+	return s.adminUpdate(ctx, actorID, id, map[string]interface{}{"hidden": hidden}, action, nil)
+}
+
+func (s *UserService) BanUser(ctx context.Context, actorID, id uuid.UUID, reason string) error {
+	updates := map[string]interface{}{
+		"banned_at":  time.Now(),
+		"ban_reason": reason,
+	}
+	return s.adminUpdate(ctx, actorID, id, updates, AuditBanUser, map[string]string{"reason": reason})
+}
+
+func (s *UserService) UnbanUser(ctx context.Context, actorID, id uuid.UUID) error {
+	updates := map[string]interface{}{
+		"banned_at":  nil,
+		"ban_reason": nil,
+	}
+	return s.adminUpdate(ctx, actorID, id, updates, AuditUnbanUser, nil)
+}
+
+// adminUpdate меняет служебные поля профиля и пишет действие в журнал в одной транзакции.
+func (s *UserService) adminUpdate(ctx context.Context, actorID, id uuid.UUID, fields map[string]interface{}, action string, details any) error {
+	return s.storage.WithTx(ctx, func(tx storage.UserStorage) error {
+		if err := ensureUserExists(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := updateUser(ctx, tx, id, fields); err != nil {
+			return err
+		}
+		return audit(ctx, tx, actorID, action, id, details)
+	})
+}
+
+// IsBanned сообщает, заблокирован ли пользователь. Несуществующий пользователь не забанен.
+func (s *UserService) IsBanned(ctx context.Context, id uuid.UUID) (bool, error) {
+	user, err := s.storage.GetUserByID(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	return user != nil && user.BannedAt != nil, nil
+}
+
+func (s *UserService) AdminRemovePhoto(ctx context.Context, actorID, userID, photoID uuid.UUID) error {
+	return s.storage.WithTx(ctx, func(tx storage.UserStorage) error {
+		if err := removePhoto(ctx, tx, userID, photoID); err != nil {
+			return err
+		}
+		return audit(ctx, tx, actorID, AuditRemovePhoto, userID, map[string]string{"photo_id": photoID.String()})
+	})
+}
+
+func (s *UserService) AdminRemoveTag(ctx context.Context, actorID, userID, tagID uuid.UUID) error {
+	return s.storage.WithTx(ctx, func(tx storage.UserStorage) error {
+		if err := s.removeTag(ctx, tx, userID, tagID); err != nil {
+			return err
+		}
+		return audit(ctx, tx, actorID, AuditRemoveTag, userID, map[string]string{"tag_id": tagID.String()})
+	})
+}
+
+func (s *UserService) ListAuditRecords(ctx context.Context, targetUserID *uuid.UUID, offset, limit int) ([]*models.AuditRecord, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > maxListLimit {
+		limit = maxListLimit
+	}
+	return s.storage.ListAuditRecords(ctx, targetUserID, offset, limit)
+}
+
+func ensureUserExists(ctx context.Context, tx storage.UserStorage, id uuid.UUID) error {
+	user, err := tx.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errUserNotFound
+	}
+	return nil
+}
+
+// audit пишет запись журнала через tx: если запись не сохранилась, откатывается и само действие.
+func audit(ctx context.Context, tx storage.UserStorage, actorID uuid.UUID, action string, targetID uuid.UUID, details any) error {
+	record := &models.AuditRecord{
+		ID:           uuid.New(),
+		ActorID:      actorID,
+		Action:       action,
+		TargetUserID: targetID,
+		CreatedAt:    time.Now(),
+	}
+
+	if details != nil {
+		data, err := json.Marshal(details)
+		if err != nil {
+			return err
+		}
+		str := string(data)
+		record.Details = &str
+	}
+
+	return tx.AddAuditRecord(ctx, record)
+}
@@ -0,0 +1,156 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/config"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/kerilOvs/profile_sevice/internal/models"
+	"github.com/kerilOvs/profile_sevice/internal/service"
+	"github.com/kerilOvs/profile_sevice/internal/storage"
+)
+
+var errAuditUnavailable = errors.New("audit unavailable")
+
+// failingAudit - хранилище, в котором не удается записать журнал аудита.
+type failingAudit struct {
+	storage.UserStorage
+}
+
+func (f failingAudit) WithTx(ctx context.Context, fn func(tx storage.UserStorage) error) error {
+	return f.UserStorage.WithTx(ctx, func(tx storage.UserStorage) error {
+		return fn(failingAudit{tx})
+	})
+}
+
+func (failingAudit) AddAuditRecord(context.Context, *models.AuditRecord) error {
+	return errAuditUnavailable
+}
+
+func TestAdminActionsAreAudited(t *testing.T) {
+	ctx := context.Background()
+	h := newHarness(config.EventsConfig{})
+	actor := uuid.New()
+	id := h.createUser(t)
+
+	tag, err := h.service.AddUserTag(ctx, id, "chess", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	photo, err := h.service.AddUserPhoto(ctx, id, "http://minio/photos/first.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.flush(t)
+
+	name := "Petr"
+	steps := []struct {
+		action string
+		run    func() error
+	}{
+		{service.AuditUpdateUser, func() error {
+			return h.service.AdminUpdateUser(ctx, actor, id, models.UserProfileUpdate{Name: &name})
+		}},
+		{service.AuditHideUser, func() error { return h.service.SetUserHidden(ctx, actor, id, true) }},
+		{service.AuditUnhideUser, func() error { return h.service.SetUserHidden(ctx, actor, id, false) }},
+		{service.AuditBanUser, func() error { return h.service.BanUser(ctx, actor, id, "spam") }},
+		{service.AuditUnbanUser, func() error { return h.service.UnbanUser(ctx, actor, id) }},
+		{service.AuditRemoveTag, func() error { return h.service.AdminRemoveTag(ctx, actor, id, tag.ID) }},
+		{service.AuditRemovePhoto, func() error { return h.service.AdminRemovePhoto(ctx, actor, id, photo.ID) }},
+		{service.AuditDeleteUser, func() error { return h.service.AdminDeleteUser(ctx, actor, id) }},
+	}
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			t.Fatalf("%s: %v", step.action, err)
+		}
+
+		records, err := h.service.ListAuditRecords(ctx, &id, 0, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 1 || records[0].Action != step.action || records[0].ActorID != actor {
+			t.Fatalf("%s: last audit record = %+v", step.action, records)
+		}
+	}
+}
+
+func TestAdminActionRolledBackWithoutAudit(t *testing.T) {
+	ctx := context.Background()
+	h := newHarness(config.EventsConfig{})
+	id := h.createUser(t)
+	tag, err := h.service.AddUserTag(ctx, id, "chess", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.flush(t)
+
+	admin := service.NewUserService(failingAudit{h.storage}, config.EventsConfig{})
+	actor := uuid.New()
+
+	for name, run := range map[string]func() error{
+		"ban":        func() error { return admin.BanUser(ctx, actor, id, "spam") },
+		"hide":       func() error { return admin.SetUserHidden(ctx, actor, id, true) },
+		"remove tag": func() error { return admin.AdminRemoveTag(ctx, actor, id, tag.ID) },
+		"delete":     func() error { return admin.AdminDeleteUser(ctx, actor, id) },
+	} {
+		if err := run(); !errors.Is(err, errAuditUnavailable) {
+			t.Fatalf("%s: err = %v, want %v", name, err, errAuditUnavailable)
+		}
+	}
+
+	user, err := h.service.GetUserByID(ctx, id)
+	if err != nil {
+		t.Fatalf("user changed without an audit record: %v", err)
+	}
+	if len(user.Tags) != 1 {
+		t.Fatalf("tags = %+v, want the tag to stay", user.Tags)
+	}
+	expectTypes(t, h.flush(t))
+}
+
+func TestHiddenAndBannedUsersHidePhotosAndTags(t *testing.T) {
+	ctx := context.Background()
+	h := newHarness(config.EventsConfig{})
+	actor := uuid.New()
+
+	for name, restrict := range map[string]func(id uuid.UUID) error{
+		"hidden": func(id uuid.UUID) error { return h.service.SetUserHidden(ctx, actor, id, true) },
+		"banned": func(id uuid.UUID) error { return h.service.BanUser(ctx, actor, id, "spam") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			id := h.createUser(t)
+			if _, err := h.service.AddUserPhoto(ctx, id, "http://minio/photos/first.jpg"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := h.service.AddUserTag(ctx, id, "chess", nil); err != nil {
+				t.Fatal(err)
+			}
+			if photos, err := h.service.GetUserPhotos(ctx, id); err != nil || len(photos) != 1 {
+				t.Fatalf("visible user photos = %v, %v", photos, err)
+			}
+
+			if err := restrict(id); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := h.service.GetUserPhotos(ctx, id); !errors.Is(err, errorsExt.ErrNotFound) {
+				t.Fatalf("GetUserPhotos err = %v, want not found", err)
+			}
+			if _, err := h.service.GetUserTags(ctx, id); !errors.Is(err, errorsExt.ErrNotFound) {
+				t.Fatalf("GetUserTags err = %v, want not found", err)
+			}
+
+			// Администратор по-прежнему видит профиль целиком
+			user, err := h.service.AdminGetUser(ctx, id)
+			if err != nil || len(user.Photos) != 1 || len(user.Tags) != 1 {
+				t.Fatalf("AdminGetUser = %+v, %v", user, err)
+			}
+		})
+	}
+
+	if _, err := h.service.GetUserTags(ctx, uuid.New()); !errors.Is(err, errorsExt.ErrNotFound) {
+		t.Fatalf("GetUserTags for a missing user: err = %v, want not found", err)
+	}
+}
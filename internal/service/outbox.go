@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/events"
+	"github.com/kerilOvs/profile_sevice/internal/models"
+	"github.com/kerilOvs/profile_sevice/internal/storage"
+)
+
+// enqueue сохраняет событие в outbox через tx, то есть в той же транзакции, что и изменение.
+func enqueue(ctx context.Context, tx storage.UserStorage, eventType string, data any) error {
+	event, err := events.New(ctx, eventType, data)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+
+	now := time.Now()
+	return tx.AddOutboxMessage(ctx, &models.OutboxMessage{
+		ID:            uuid.MustParse(event.ID),
+		Topic:         event.Type,
+		Payload:       body,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	})
+}
+
+type pendingEvent struct {
+	eventType string
+	data      any
+}
+
+func enqueueAll(ctx context.Context, tx storage.UserStorage, pending []pendingEvent) error {
+	for _, event := range pending {
+		if err := enqueue(ctx, tx, event.eventType, event.data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enqueueTags публикует актуальный набор тегов пользователя с новой версией набора.
+func (s *UserService) enqueueTags(ctx context.Context, tx storage.UserStorage, userID uuid.UUID) error {
+	version, err := tx.BumpTagsVersion(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	tags, err := tx.GetUserTags(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return enqueueAll(ctx, tx, s.tagsEvents(userID, version, tags))
+}
+
+// tagsEvents - теги в формате v2 и, пока включен legacyTags, строкой в старом формате.
+func (s *UserService) tagsEvents(userID uuid.UUID, version int64, tags []*models.UserTag) []pendingEvent {
+	v2 := events.TagsUpdatedV2{
+		UserID:  userID,
+		Version: version,
+		Tags:    make([]events.Tag, 0, len(tags)),
+	}
+	for _, tag := range tags {
+		if tag != nil {
+			v2.Tags = append(v2.Tags, events.Tag{ID: tag.ID, Value: tag.Value, Category: tag.Category})
+		}
+	}
+
+	result := []pendingEvent{{events.TypeTagsUpdatedV2, v2}}
+	if s.legacyTags {
+		result = append(result, pendingEvent{events.TypeTagsUpdated, events.TagsUpdated{
+			UserID: userID,
+			Tags:   ConcatenateTagValues(tags),
+		}})
+	}
+	return result
+}
+
+// updateUser меняет поля профиля и публикует profile.user.updated со списком измененных полей.
+// Ключи fields - колонки users, они совпадают с именами полей в API.
+func updateUser(ctx context.Context, tx storage.UserStorage, id uuid.UUID, fields map[string]interface{}) error {
+	if err := tx.UpdateUser(ctx, id, fields); err != nil {
+		return err
+	}
+	return enqueueUpdated(ctx, tx, id, slices.Sorted(maps.Keys(fields))...)
+}
+
+func enqueueUpdated(ctx context.Context, tx storage.UserStorage, id uuid.UUID, fields ...string) error {
+	return enqueue(ctx, tx, events.TypeUserUpdated, events.UserUpdated{UserID: id, ChangedFields: fields})
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/kerilOvs/profile_sevice/internal/config"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
 
 	"github.com/google/uuid"
 	"github.com/minio/minio-go/v7"
@@ -48,7 +49,7 @@ func (s *PhotoService) UploadPhoto(ctx context.Context, file io.Reader, size int
 	)
 
 	if err != nil {
-		return "", fmt.Errorf("upload failed: %w", err)
+		return "", errorsExt.Upstream(errorsExt.CodeStorageUnavailable, "upload failed", err)
 	}
 
 	return objectName, nil
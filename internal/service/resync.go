@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/events"
+	"github.com/kerilOvs/profile_sevice/internal/models"
+	"github.com/kerilOvs/profile_sevice/internal/storage"
+)
+
+const defaultResyncBatch = 500
+
+// ResyncOptions - параметры полной переотправки профилей сервису подбора.
+type ResyncOptions struct {
+	After     uuid.UUID // курсор: начать с пользователя, следующего за этим id
+	BatchSize int
+	Rate      int  // пользователей в секунду, записываемых в outbox, 0 - без ограничения
+	DryRun    bool // только посчитать, ничего не записывать
+	// Progress вызывается после каждой пачки
+	Progress func(ResyncProgress)
+}
+
+// ResyncProgress - сколько обработано. Cursor - id последнего обработанного пользователя,
+// с него можно продолжить после остановки.
+type ResyncProgress struct {
+	Cursor uuid.UUID
+	Users  int
+	Events int
+}
+
+// Resync обходит всех пользователей по возрастанию id и для каждого заново ставит в outbox
+// анкету, теги и главное фото. Каждая пачка пишется в своей транзакции, поэтому при
+// ошибке достаточно перезапустить с возвращенного Cursor.
+func (s *UserService) Resync(ctx context.Context, opts ResyncOptions) (ResyncProgress, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultResyncBatch
+	}
+
+	progress := ResyncProgress{Cursor: opts.After}
+	started := time.Now()
+	for {
+		users, err := s.storage.ListUsersAfter(ctx, progress.Cursor, opts.BatchSize)
+		if err != nil {
+			return progress, err
+		}
+		if len(users) == 0 {
+			return progress, nil
+		}
+
+		var count int
+		if opts.DryRun {
+			for _, user := range users {
+				count += len(s.resyncEvents(user))
+			}
+		} else {
+			err = s.storage.WithTx(ctx, func(tx storage.UserStorage) error {
+				count = 0
+				for _, user := range users {
+					pending := s.resyncEvents(user)
+					if err := enqueueAll(ctx, tx, pending); err != nil {
+						return err
+					}
+					count += len(pending)
+				}
+				return nil
+			})
+			if err != nil {
+				return progress, err
+			}
+		}
+
+		progress.Cursor = users[len(users)-1].ID
+		progress.Users += len(users)
+		progress.Events += count
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+
+		if err := throttle(ctx, started, progress.Users, opts.Rate); err != nil {
+			return progress, err
+		}
+	}
+}
+
+// resyncEvents - события сервиса подбора для пользователя. Теги должны быть загружены,
+// версия набора тегов не меняется.
+func (s *UserService) resyncEvents(user *models.User) []pendingEvent {
+	tags := make([]*models.UserTag, len(user.Tags))
+	for i := range user.Tags {
+		tags[i] = &user.Tags[i]
+	}
+
+	result := s.anketEvents(user)
+	result = append(result, s.tagsEvents(user.ID, user.TagsVersion, tags)...)
+	if user.PrimaryPhoto != nil {
+		result = append(result, pendingEvent{events.TypePhotoUpdated, events.PhotoUpdated{UserID: user.ID, ImageURL: *user.PrimaryPhoto}})
+	}
+	return result
+}
+
+// throttle ждет, пока средняя скорость с момента started не опустится до rate пользователей в секунду.
+func throttle(ctx context.Context, started time.Time, done, rate int) error {
+	if rate <= 0 {
+		return nil
+	}
+
+	wait := time.Until(started.Add(time.Duration(done) * time.Second / time.Duration(rate)))
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,111 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/config"
+	"github.com/kerilOvs/profile_sevice/internal/events"
+	"github.com/kerilOvs/profile_sevice/internal/service"
+)
+
+// byUser раскладывает опубликованные события по пользователям.
+func byUser(t *testing.T, published []events.Envelope) map[uuid.UUID][]events.Envelope {
+	t.Helper()
+
+	result := map[uuid.UUID][]events.Envelope{}
+	for _, event := range published {
+		id := data[struct {
+			UserID uuid.UUID `json:"user_id"`
+		}](t, event).UserID
+		result[id] = append(result[id], event)
+	}
+	return result
+}
+
+func TestResync(t *testing.T) {
+	ctx := context.Background()
+	h := newHarness(config.EventsConfig{LegacyTags: true, LegacyAnket: true})
+
+	tagged, withPhoto, plain := h.createUser(t), h.createUser(t), h.createUser(t)
+	if _, err := h.service.AddUserTag(ctx, tagged, "chess", nil); err != nil {
+		t.Fatal(err)
+	}
+	photo, err := h.service.AddUserPhoto(ctx, withPhoto, "http://minio/photos/1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.service.SetPrimaryPhoto(ctx, withPhoto, photo.ID); err != nil {
+		t.Fatal(err)
+	}
+	h.flush(t)
+
+	var batches []service.ResyncProgress
+	progress, err := h.service.Resync(ctx, service.ResyncOptions{
+		BatchSize: 2,
+		Progress:  func(p service.ResyncProgress) { batches = append(batches, p) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.Users != 3 || len(batches) != 2 || batches[0].Users != 2 || batches[1] != progress {
+		t.Fatalf("progress = %+v, batches = %+v", progress, batches)
+	}
+
+	published := h.flush(t)
+	if progress.Events != len(published) {
+		t.Fatalf("progress counts %d events, published %d", progress.Events, len(published))
+	}
+	users := byUser(t, published)
+	expectTypes(t, users[plain], events.TypeAnketUpdated, events.TypeTagsUpdatedV2, events.TypeTagsUpdated)
+	expectTypes(t, users[withPhoto], events.TypeAnketUpdated, events.TypeTagsUpdatedV2, events.TypeTagsUpdated, events.TypePhotoUpdated)
+	if updated := data[events.PhotoUpdated](t, users[withPhoto][3]); updated.ImageURL != photo.URL {
+		t.Errorf("photo updated = %+v", updated)
+	}
+	// Версия тегов не растет: получатель уже видел это состояние
+	expectTypes(t, users[tagged], events.TypeAnketUpdated, events.TypeTagsUpdatedV2, events.TypeTagsUpdated)
+	if v2 := data[events.TagsUpdatedV2](t, users[tagged][1]); v2.Version != 1 || len(v2.Tags) != 1 {
+		t.Errorf("tags v2 = %+v", v2)
+	}
+
+	t.Run("resume after cursor", func(t *testing.T) {
+		resumed, err := h.service.Resync(ctx, service.ResyncOptions{After: batches[0].Cursor, BatchSize: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		users := byUser(t, h.flush(t))
+		if resumed.Users != 1 || len(users) != 1 || users[progress.Cursor] == nil {
+			t.Fatalf("resumed = %+v, events for %d users, want only the last user", resumed, len(users))
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		dry, err := h.service.Resync(ctx, service.ResyncOptions{DryRun: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dry.Users != 3 || dry.Events != progress.Events {
+			t.Fatalf("dry run = %+v, want %d events", dry, progress.Events)
+		}
+		expectTypes(t, h.flush(t))
+	})
+
+	t.Run("stopped keeps finished batches", func(t *testing.T) {
+		stopCtx, stop := context.WithCancel(ctx)
+		defer stop()
+
+		stopped, err := h.service.Resync(stopCtx, service.ResyncOptions{
+			BatchSize: 1,
+			Rate:      1,
+			Progress:  func(service.ResyncProgress) { stop() },
+		})
+		if !errors.Is(err, context.Canceled) || stopped.Users != 1 {
+			t.Fatalf("stopped = %+v, %v, want one batch and context.Canceled", stopped, err)
+		}
+		if published := h.flush(t); len(published) != stopped.Events {
+			t.Fatalf("published %d events, want %d from the finished batch", len(published), stopped.Events)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/kerilOvs/profile_sevice/internal/models"
+	"github.com/kerilOvs/profile_sevice/internal/storage"
+)
+
+var errInvalidCursor = errorsExt.Validation(errorsExt.CodeInvalidCursor, "invalid cursor",
+	errorsExt.FieldError{Field: "cursor", In: "query", Message: "must be next_cursor from a previous page"})
+
+// UserSearch - фильтры GET /users. Пустое поле не фильтрует.
+type UserSearch struct {
+	Gender *models.UserGender
+	// Возраст в полных годах на сегодня (UTC), считается по BirthDate
+	AgeMin     *int
+	AgeMax     *int
+	JungResult *string
+	Tags       []string
+	AllTags    bool // все теги сразу, по умолчанию - любой из них
+	// CreatedAfter включительно, CreatedBefore - нет
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	IncludeHidden bool
+
+	Cursor string
+	Limit  int
+}
+
+// cursor - то, что прячется в непрозрачной строке next_cursor.
+type cursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        uuid.UUID `json:"i"`
+}
+
+// SearchUsers отдает страницу пользователей от новых к старым и курсор следующей,
+// пустой на последней странице.
+func (s *UserService) SearchUsers(ctx context.Context, search UserSearch) ([]*models.User, string, error) {
+	filter, err := search.filter(time.Now())
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Берем на одного больше, чтобы без отдельного запроса понять, есть ли следующая страница
+	limit := filter.Limit
+	filter.Limit++
+	users, err := s.storage.SearchUsers(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(users) <= limit {
+		return users, "", nil
+	}
+
+	users = users[:limit]
+	last := users[limit-1]
+	next, err := json.Marshal(cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	if err != nil {
+		return nil, "", err
+	}
+	return users, base64.RawURLEncoding.EncodeToString(next), nil
+}
+
+func (q UserSearch) filter(now time.Time) (storage.UserFilter, error) {
+	filter := storage.UserFilter{
+		Gender:        q.Gender,
+		Tags:          q.Tags,
+		AllTags:       q.AllTags,
+		CreatedFrom:   q.CreatedAfter,
+		CreatedTo:     q.CreatedBefore,
+		IncludeHidden: q.IncludeHidden,
+		Limit:         q.Limit,
+	}
+	if filter.Limit <= 0 || filter.Limit > maxListLimit {
+		filter.Limit = maxListLimit
+	}
+
+	if q.AgeMin != nil && q.AgeMax != nil && *q.AgeMin > *q.AgeMax {
+		return filter, errorsExt.Validation(errorsExt.CodeRequestValidation, "age_min is greater than age_max",
+			errorsExt.FieldError{Field: "age_min", In: "query", Message: "must not be greater than age_max"})
+	}
+	// Возраст не меньше min - родился не позже чем min лет назад, не больше max - позже чем max+1 лет назад
+	today := now.UTC().Truncate(24 * time.Hour)
+	if q.AgeMin != nil {
+		bornTo := today.AddDate(-*q.AgeMin, 0, 1)
+		filter.BornTo = &bornTo
+	}
+	if q.AgeMax != nil {
+		bornFrom := today.AddDate(-*q.AgeMax-1, 0, 1)
+		filter.BornFrom = &bornFrom
+	}
+
+	if q.JungResult != nil {
+		if !isValidJungType(*q.JungResult) {
+			return filter, errorsExt.Validation(errorsExt.CodeInvalidJung, "invalid Jung personality type",
+				errorsExt.FieldError{Field: "jung_result", In: "query", Message: "unknown personality type"})
+		}
+		filter.JungResult = q.JungResult
+	}
+
+	if q.Cursor != "" {
+		data, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+		if err != nil {
+			return filter, errInvalidCursor
+		}
+		var c cursor
+		if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil {
+			return filter, errInvalidCursor
+		}
+		filter.After = &storage.UserCursor{CreatedAt: c.CreatedAt, ID: c.ID}
+	}
+
+	return filter, nil
+}
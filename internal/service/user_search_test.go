@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/config"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/kerilOvs/profile_sevice/internal/service"
+)
+
+func TestSearchUsersCursorRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	h := newHarness(config.EventsConfig{})
+
+	created := map[uuid.UUID]bool{}
+	for range 5 {
+		created[h.createUser(t)] = true
+	}
+
+	seen := map[uuid.UUID]bool{}
+	var pages int
+	search := service.UserSearch{Limit: 2}
+	for {
+		users, next, err := h.service.SearchUsers(ctx, search)
+		if err != nil {
+			t.Fatalf("page %d: %v", pages+1, err)
+		}
+		pages++
+		for i, user := range users {
+			if seen[user.ID] {
+				t.Fatalf("user %s is returned twice", user.ID)
+			}
+			seen[user.ID] = true
+			// От новых к старым, при равном времени - по id
+			if i > 0 && users[i-1].CreatedAt.Before(user.CreatedAt) {
+				t.Fatalf("page %d is not ordered by created_at desc", pages)
+			}
+		}
+		if next == "" {
+			break
+		}
+		if next == search.Cursor || pages > len(created) {
+			t.Fatalf("cursor does not advance: %q", next)
+		}
+		search.Cursor = next
+	}
+
+	if pages != 3 || len(seen) != len(created) {
+		t.Fatalf("%d pages with %d users, want 3 pages with %d", pages, len(seen), len(created))
+	}
+	for id := range created {
+		if !seen[id] {
+			t.Fatalf("user %s is missing from the pages", id)
+		}
+	}
+}
+
+func TestSearchUsersRejectsTamperedCursor(t *testing.T) {
+	ctx := context.Background()
+	h := newHarness(config.EventsConfig{})
+	for range 3 {
+		h.createUser(t)
+	}
+
+	_, next, err := h.service.SearchUsers(ctx, service.UserSearch{Limit: 1})
+	if err != nil || next == "" {
+		t.Fatalf("first page: cursor %q, %v", next, err)
+	}
+
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	cursors := map[string]string{
+		"not base64":    "%%%",
+		"std base64":    base64.StdEncoding.EncodeToString([]byte(`{"c":"2025-01-01T00:00:00Z","i":"` + uuid.NewString() + `"}`)),
+		"truncated":     next[:len(next)/2],
+		"extra data":    next + "AA",
+		"not json":      encode("created_at=2025-01-01"),
+		"missing id":    encode(`{"c":"2025-01-01T00:00:00Z"}`),
+		"nil id":        encode(`{"c":"2025-01-01T00:00:00Z","i":"` + uuid.Nil.String() + `"}`),
+		"invalid id":    encode(`{"c":"2025-01-01T00:00:00Z","i":"42"}`),
+		"invalid time":  encode(`{"c":"yesterday","i":"` + uuid.NewString() + `"}`),
+		"flipped chars": strings.ToUpper(next),
+	}
+	for name, value := range cursors {
+		t.Run(name, func(t *testing.T) {
+			users, _, err := h.service.SearchUsers(ctx, service.UserSearch{Cursor: value, Limit: 1})
+			if !errors.Is(err, errorsExt.ErrValidation) || users != nil {
+				t.Fatalf("SearchUsers = %d users, %v, want a validation error", len(users), err)
+			}
+			if domainErr, _ := errorsExt.As(err); domainErr.Code != errorsExt.CodeInvalidCursor {
+				t.Fatalf("code = %q, want %q", domainErr.Code, errorsExt.CodeInvalidCursor)
+			}
+		})
+	}
+}
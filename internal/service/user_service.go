@@ -1,31 +1,42 @@
 package service
 
 import (
-	"errors"
+	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
-	"context"
-
 	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/config"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/kerilOvs/profile_sevice/internal/events"
 	"github.com/kerilOvs/profile_sevice/internal/models"
 	"github.com/kerilOvs/profile_sevice/internal/storage"
-	"github.com/kerilOvs/profile_sevice/internal/storage/rabbit"
 )
 
+var (
+	errUserNotFound    = errorsExt.NotFound(errorsExt.CodeUserNotFound, "user not found")
+	errPhotoNotFound   = errorsExt.NotFound(errorsExt.CodePhotoNotFound, "photo not found")
+	errNameRequired    = errorsExt.Validation(errorsExt.CodeNameRequired, "name cannot be empty")
+	errSurnameRequired = errorsExt.Validation(errorsExt.CodeSurnameRequired, "surname cannot be empty")
+)
+
+// UserService не публикует события напрямую: они пишутся в outbox в той же транзакции,
+// что и изменение, а в RabbitMQ их отправляет outbox.Relay.
 type UserService struct {
-	storage    storage.UserStorage
-	rabbitRepo *rabbit.Repo
+	storage     storage.UserStorage
+	legacyTags  bool
+	legacyAnket bool
 }
 
-func NewUserService(storage storage.UserStorage, rabbit *rabbit.Repo) *UserService {
-	return &UserService{storage: storage, rabbitRepo: rabbit}
+func NewUserService(storage storage.UserStorage, cfg config.EventsConfig) *UserService {
+	return &UserService{storage: storage, legacyTags: cfg.LegacyTags, legacyAnket: cfg.LegacyAnket}
 }
 
-func (s *UserService) CreateUser(id uuid.UUID, name, surname string, aboutMyself *string, gender *models.UserGender) (*models.User, error) {
+func (s *UserService) CreateUser(ctx context.Context, id uuid.UUID, name, surname string, aboutMyself *string, gender *models.UserGender) (*models.User, error) {
 	if name == "" || surname == "" {
-		return nil, errors.New("name and surname are required")
+		return nil, errorsExt.Validation(errorsExt.CodeNameRequired, "name and surname are required")
 	}
 
 	user := &models.User{
@@ -37,47 +48,131 @@ func (s *UserService) CreateUser(id uuid.UUID, name, surname string, aboutMyself
 		CreatedAt:   time.Now(),
 	}
 
-	if err := s.storage.CreateUser(user); err != nil {
-		return nil, err
+	// Анкету v2 при создании не публикуем: даты рождения еще нет
+	legacyGender := models.GenderFemale
+	if gender != nil {
+		legacyGender = *gender
+	}
+	legacyAnket := events.AnketUpdated{
+		UserID:    id,
+		Gender:    legacyGender,
+		BirthDate: "01/01/2000",
 	}
 
-	if gender == nil {
-		female := models.GenderFemale
-		gender = &female
+	created := events.UserCreated{
+		UserID:    id,
+		Name:      name,
+		Surname:   surname,
+		Gender:    user.Gender,
+		CreatedAt: user.CreatedAt,
 	}
-	anket := rabbit.UserAnket{
-		ID:        id,
-		Gender:    *gender,
-		BirthDate: "01/01/2000",
+
+	err := s.storage.WithTx(ctx, func(tx storage.UserStorage) error {
+		if err := tx.CreateUser(ctx, user); err != nil {
+			return err
+		}
+		if err := enqueue(ctx, tx, events.TypeUserCreated, created); err != nil {
+			return err
+		}
+		if !s.legacyAnket {
+			return nil
+		}
+		return enqueue(ctx, tx, events.TypeAnketUpdated, legacyAnket)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// rabbit.PublishAnket()
-	ctx := context.TODO()
+	return user, nil
+}
 
-	err := s.rabbitRepo.PublishAnket(ctx, anket)
+func (s *UserService) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	user, err := s.getUserWithRelations(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	// Скрытые и забаненные профили не видны обычным пользователям
+	if user.Hidden || user.BannedAt != nil {
+		return nil, errUserNotFound
+	}
+
 	return user, nil
 }
 
-func (s *UserService) GetUserByID(id uuid.UUID) (*models.User, error) {
-	user, err := s.storage.GetUserByID(id)
+// maxBatchGet - сколько профилей можно запросить в BatchGetUsers за раз
+const maxBatchGet = 100
+
+// BatchGetUsers отдает короткие профили в порядке ids одним запросом к базе. Как и в
+// GetUserByID, скрытые и забаненные профили не видны: они попадают в missing вместе с несуществующими.
+func (s *UserService) BatchGetUsers(ctx context.Context, ids []uuid.UUID) ([]*models.UserSummary, []uuid.UUID, error) {
+	if len(ids) > maxBatchGet {
+		return nil, nil, errorsExt.Validation(errorsExt.CodeRequestValidation, "too many ids",
+			errorsExt.FieldError{Field: "ids", In: "body", Message: fmt.Sprintf("must contain at most %d ids", maxBatchGet)})
+	}
+
+	seen := make(map[uuid.UUID]bool, len(ids))
+	ids = slices.DeleteFunc(slices.Clone(ids), func(id uuid.UUID) bool {
+		duplicate := seen[id]
+		seen[id] = true
+		return duplicate
+	})
+
+	users, err := s.storage.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[uuid.UUID]*models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	summaries := make([]*models.UserSummary, 0, len(users))
+	missing := []uuid.UUID{}
+	for _, id := range ids {
+		user, ok := byID[id]
+		if !ok || user.Hidden || user.BannedAt != nil {
+			missing = append(missing, id)
+			continue
+		}
+		summaries = append(summaries, summaryOf(user))
+	}
+	return summaries, missing, nil
+}
+
+func summaryOf(user *models.User) *models.UserSummary {
+	tags := user.Tags
+	if tags == nil {
+		tags = []models.UserTag{}
+	}
+	return &models.UserSummary{
+		ID:           user.ID,
+		Name:         user.Name,
+		Surname:      user.Surname,
+		Gender:       user.Gender,
+		BirthDate:    user.BirthDate,
+		JungResult:   user.JungResult,
+		PrimaryPhoto: user.PrimaryPhoto,
+		Tags:         tags,
+	}
+}
+
+func (s *UserService) getUserWithRelations(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	user, err := s.storage.GetUserByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	if user == nil {
-		return nil, errors.New("user not found")
+		return nil, errUserNotFound
 	}
 
 	// Загружаем связанные данные
-	photos, err := s.storage.GetUserPhotos(id)
+	photos, err := s.storage.GetUserPhotos(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	tags, err := s.storage.GetUserTags(id)
+	tags, err := s.storage.GetUserTags(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -104,19 +199,26 @@ func (s *UserService) GetUserByID(id uuid.UUID) (*models.User, error) {
 	return user, nil
 }
 
-func (s *UserService) UpdateUserProfile(id uuid.UUID, updates models.UserProfileUpdate) error {
+func (s *UserService) UpdateUserProfile(ctx context.Context, id uuid.UUID, updates models.UserProfileUpdate) error {
+	return s.storage.WithTx(ctx, func(tx storage.UserStorage) error {
+		return s.updateProfile(ctx, tx, id, updates)
+	})
+}
+
+// updateProfile - UpdateUserProfile в уже открытой транзакции tx.
+func (s *UserService) updateProfile(ctx context.Context, tx storage.UserStorage, id uuid.UUID, updates models.UserProfileUpdate) error {
 	updateFields := make(map[string]interface{})
 
 	if updates.Name != nil {
 		if *updates.Name == "" {
-			return errors.New("name cannot be empty")
+			return errNameRequired
 		}
 		updateFields["name"] = *updates.Name
 	}
 
 	if updates.Surname != nil {
 		if *updates.Surname == "" {
-			return errors.New("surname cannot be empty")
+			return errSurnameRequired
 		}
 		updateFields["surname"] = *updates.Surname
 	}
@@ -133,83 +235,88 @@ func (s *UserService) UpdateUserProfile(id uuid.UUID, updates models.UserProfile
 		updateFields["birth_date"] = *updates.BirthDate
 	}
 
+	var jung *events.JungUpdated
 	if updates.JungResult != nil {
 		if !isValidJungType(*updates.JungResult) {
-			return errors.New("invalid Jung personality type")
+			return errorsExt.Validation(errorsExt.CodeInvalidJung, "invalid Jung personality type",
+				errorsExt.FieldError{Field: "jung_result", In: "body", Message: "unknown personality type"})
 		}
 		updateFields["jung_result"] = *updates.JungResult
 		now := time.Now()
 		updateFields["jung_last_attempt"] = now
+		jung = &events.JungUpdated{UserID: id, JungResult: *updates.JungResult, AttemptAt: now}
 	}
 
-	if updates.BirthDate != nil && updates.Gender != nil {
-		temp := *updates.BirthDate
-		formatted := fmt.Sprintf("%02d/%02d/%04d", temp.Day(), temp.Month(), temp.Year())
-		anket := rabbit.UserAnket{
-			ID:        id,
-			Gender:    *updates.Gender,
-			BirthDate: formatted,
-		}
-		ctx := context.TODO()
-		err := s.rabbitRepo.PublishAnket(ctx, anket)
-		if err != nil {
-			return err
-		}
-	} else if updates.BirthDate != nil {
-		uzer, err := s.GetUserByID(id)
-		if err != nil {
-			return err
-		}
-		temp := *updates.BirthDate
-		formatted := fmt.Sprintf("%02d/%02d/%04d", temp.Day(), temp.Month(), temp.Year())
+	if len(updateFields) == 0 {
+		return nil
+	}
 
-		if uzer.Gender == nil {
-			female := models.GenderFemale
-			uzer.Gender = &female
-		}
-		anket := rabbit.UserAnket{
-			ID:        id,
-			Gender:    *uzer.Gender,
-			BirthDate: formatted,
-		}
-		ctx := context.TODO()
-		err = s.rabbitRepo.PublishAnket(ctx, anket)
-		if err != nil {
-			return err
-		}
-	} else if updates.Gender != nil {
+	if err := updateUser(ctx, tx, id, updateFields); err != nil {
+		return err
+	}
 
-		uzer, err := s.GetUserByID(id)
-		if err != nil {
-			return err
-		}
-		if uzer.BirthDate == nil {
-			uzer.BirthDate = &time.Time{}
-		}
-		temp := *uzer.BirthDate
-		formatted := fmt.Sprintf("%02d/%02d/%04d", temp.Day(), temp.Month(), temp.Year())
-		anket := rabbit.UserAnket{
-			ID:        id,
-			Gender:    *updates.Gender,
-			BirthDate: formatted,
-		}
-		ctx := context.TODO()
-		err = s.rabbitRepo.PublishAnket(ctx, anket)
-		if err != nil {
+	if jung != nil {
+		if err := enqueue(ctx, tx, events.TypeJungUpdated, jung); err != nil {
 			return err
 		}
 	}
 
-	if len(updateFields) > 0 {
-		return s.storage.UpdateUser(id, updateFields)
+	// Анкета нужна сервису подбора, только если поменялись ее поля
+	if updates.BirthDate == nil && updates.Gender == nil && updates.JungResult == nil {
+		return nil
 	}
 
-	return nil
+	user, err := tx.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errUserNotFound
+	}
+	return enqueueAll(ctx, tx, s.anketEvents(user))
+}
+
+// anketEvents - анкета v2, если заполнены пол и дата рождения, и, пока включен legacyAnket,
+// анкета в старом формате.
+func (s *UserService) anketEvents(user *models.User) []pendingEvent {
+	var result []pendingEvent
+	if user.Gender != nil && user.BirthDate != nil {
+		result = append(result, pendingEvent{events.TypeAnketUpdatedV2, events.AnketUpdatedV2{
+			UserID:     user.ID,
+			Gender:     *user.Gender,
+			BirthDate:  user.BirthDate.UTC(),
+			JungResult: user.JungResult,
+		}})
+	}
+	if s.legacyAnket {
+		result = append(result, pendingEvent{events.TypeAnketUpdated, legacyAnketOf(user)})
+	}
+	return result
 }
 
-func (s *UserService) AddUserPhoto(userID uuid.UUID, photoURL string) (*models.UserPhoto, error) {
+// legacyAnketOf собирает анкету старого формата. Пустой пол считается женским,
+// пустая дата рождения - нулевой датой.
+func legacyAnketOf(user *models.User) events.AnketUpdated {
+	gender := models.GenderFemale
+	if user.Gender != nil {
+		gender = *user.Gender
+	}
+
+	var birthDate time.Time
+	if user.BirthDate != nil {
+		birthDate = *user.BirthDate
+	}
+
+	return events.AnketUpdated{
+		UserID:    user.ID,
+		Gender:    gender,
+		BirthDate: fmt.Sprintf("%02d/%02d/%04d", birthDate.Day(), birthDate.Month(), birthDate.Year()),
+	}
+}
+
+func (s *UserService) AddUserPhoto(ctx context.Context, userID uuid.UUID, photoURL string) (*models.UserPhoto, error) {
 	if photoURL == "" {
-		return nil, errors.New("photo URL cannot be empty")
+		return nil, errorsExt.Validation(errorsExt.CodeInvalidPhoto, "photo URL cannot be empty")
 	}
 
 	photo := &models.UserPhoto{
@@ -218,74 +325,74 @@ func (s *UserService) AddUserPhoto(userID uuid.UUID, photoURL string) (*models.U
 		URL:    photoURL,
 	}
 
-	if err := s.storage.AddPhoto(photo); err != nil {
+	err := s.storage.WithTx(ctx, func(tx storage.UserStorage) error {
+		if err := tx.AddPhoto(ctx, photo); err != nil {
+			return err
+		}
+		return enqueue(ctx, tx, events.TypePhotoAdded, events.PhotoAdded{
+			UserID:  userID,
+			PhotoID: photo.ID,
+			URL:     photo.URL,
+		})
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return photo, nil
 }
 
-func (s *UserService) SetPrimaryPhoto(userID, photoID uuid.UUID) error {
-	photos, err := s.storage.GetUserPhotos(userID)
-	if err != nil {
-		return err
-	}
-
-	var photoURL string
-	for _, photo := range photos {
-		if photo.ID == photoID {
-			photoURL = photo.URL
-			break
+func (s *UserService) SetPrimaryPhoto(ctx context.Context, userID, photoID uuid.UUID) error {
+	return s.storage.WithTx(ctx, func(tx storage.UserStorage) error {
+		photos, err := tx.GetUserPhotos(ctx, userID)
+		if err != nil {
+			return err
 		}
-	}
-
-	if photoURL == "" {
-		return errors.New("photo not found")
-	}
 
-	photo := rabbit.Photo{
-		ID:   userID,
-		Path: photoURL,
-	}
+		var photoURL string
+		for _, photo := range photos {
+			if photo.ID == photoID {
+				photoURL = photo.URL
+				break
+			}
+		}
 
-	ctx := context.TODO()
-	err = s.rabbitRepo.PublishPhoto(ctx, photo)
-	if err != nil {
-		return err
-	}
+		if photoURL == "" {
+			return errPhotoNotFound
+		}
 
-	return s.storage.SetPrimaryPhoto(userID, photoURL)
+		if err := tx.SetPrimaryPhoto(ctx, userID, photoURL); err != nil {
+			return err
+		}
+		return enqueue(ctx, tx, events.TypePhotoUpdated, events.PhotoUpdated{
+			UserID:   userID,
+			ImageURL: photoURL,
+		})
+	})
 }
 
-func (s *UserService) AddUserTag(userID uuid.UUID, tagValue string) (*models.UserTag, error) {
+func (s *UserService) AddUserTag(ctx context.Context, userID uuid.UUID, tagValue string, category *string) (*models.UserTag, error) {
 	if tagValue == "" {
-		return nil, errors.New("tag cannot be empty")
+		return nil, errorsExt.Validation(errorsExt.CodeTagRequired, "tag cannot be empty",
+			errorsExt.FieldError{Field: "tag", In: "body", Message: "must not be empty"})
 	}
 
 	tag := &models.UserTag{
-		ID:     uuid.New(),
-		UserID: userID,
-		Value:  tagValue,
+		ID:       uuid.New(),
+		UserID:   userID,
+		Value:    tagValue,
+		Category: category,
 	}
 
-	if err := s.storage.AddTag(tag); err != nil {
-		return nil, err
-	}
-
-	tags_list, err := s.GetUserTags(userID)
+	err := s.storage.WithTx(ctx, func(tx storage.UserStorage) error {
+		if err := tx.AddTag(ctx, tag); err != nil {
+			return err
+		}
+		return s.enqueueTags(ctx, tx, userID)
+	})
 	if err != nil {
 		return nil, err
 	}
-	result := ConcatenateTagValues(tags_list)
-	tags := rabbit.Tags{
-		UserID: userID,
-		Tags:   result,
-	}
-	ctx := context.TODO()
-	err = s.rabbitRepo.PublishTags(ctx, tags)
-	if err != nil {
-		return tag, err
-	}
 
 	return tag, nil
 }
@@ -300,98 +407,156 @@ func isValidJungType(jungType string) bool {
 	return validTypes[jungType]
 }
 
-func (s *UserService) DeleteUser(id uuid.UUID) error {
-	return s.storage.DeleteUser(id)
+func (s *UserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	return s.storage.WithTx(ctx, func(tx storage.UserStorage) error {
+		return deleteUser(ctx, tx, id)
+	})
 }
 
-func (s *UserService) UpdateUserAbout(id uuid.UUID, about string) error {
-	return s.storage.UpdateUserAbout(id, about)
+func deleteUser(ctx context.Context, tx storage.UserStorage, id uuid.UUID) error {
+	if err := tx.DeleteUser(ctx, id); err != nil {
+		return err
+	}
+	return enqueue(ctx, tx, events.TypeUserDeleted, events.UserDeleted{UserID: id})
 }
 
-func (s *UserService) UpdateUserName(id uuid.UUID, name string) error {
+func (s *UserService) UpdateUserAbout(ctx context.Context, id uuid.UUID, about string) error {
+	return s.storage.WithTx(ctx, func(tx storage.UserStorage) error {
+		if err := tx.UpdateUserAbout(ctx, id, about); err != nil {
+			return err
+		}
+		return enqueueUpdated(ctx, tx, id, "about_myself")
+	})
+}
+
+func (s *UserService) UpdateUserName(ctx context.Context, id uuid.UUID, name string) error {
 	if name == "" {
-		return errors.New("name cannot be empty")
+		return errNameRequired
 	}
-	return s.storage.UpdateUserName(id, name)
+	return s.storage.WithTx(ctx, func(tx storage.UserStorage) error {
+		if err := tx.UpdateUserName(ctx, id, name); err != nil {
+			return err
+		}
+		return enqueueUpdated(ctx, tx, id, "name")
+	})
 }
 
-func (s *UserService) UpdateUserSurname(id uuid.UUID, surname string) error {
+func (s *UserService) UpdateUserSurname(ctx context.Context, id uuid.UUID, surname string) error {
 	if surname == "" {
-		return errors.New("surname cannot be empty")
+		return errSurnameRequired
 	}
-	return s.storage.UpdateUserSurname(id, surname)
+	return s.storage.WithTx(ctx, func(tx storage.UserStorage) error {
+		if err := tx.UpdateUserSurname(ctx, id, surname); err != nil {
+			return err
+		}
+		return enqueueUpdated(ctx, tx, id, "surname")
+	})
+}
+
+// GetUserPhotos, как и GetUserByID, не показывает фото скрытых и забаненных профилей.
+func (s *UserService) GetUserPhotos(ctx context.Context, userID uuid.UUID) ([]*models.UserPhoto, error) {
+	if err := s.ensureVisible(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.storage.GetUserPhotos(ctx, userID)
 }
 
-func (s *UserService) GetUserPhotos(userID uuid.UUID) ([]*models.UserPhoto, error) {
-	return s.storage.GetUserPhotos(userID)
+func (s *UserService) RemoveUserPhoto(ctx context.Context, userID, photoID uuid.UUID) error {
+	return s.storage.WithTx(ctx, func(tx storage.UserStorage) error {
+		return removePhoto(ctx, tx, userID, photoID)
+	})
 }
 
-func (s *UserService) RemoveUserPhoto(userID, photoID uuid.UUID) error {
-	// rem_photo, err := s.storage.GetUserPhotoByID(photoID)
-	user, err := s.storage.GetUserByID(userID)
+func removePhoto(ctx context.Context, tx storage.UserStorage, userID, photoID uuid.UUID) error {
+	user, err := tx.GetUserByID(ctx, userID)
 	if err != nil {
 		return err
 	}
-	if user.PrimaryPhoto != nil && !strings.Contains(*user.PrimaryPhoto, photoID.String()) {
-		photo := rabbit.Photo{
-			ID:   userID,
-			Path: *user.PrimaryPhoto,
+	if user == nil {
+		return errUserNotFound
+	}
+
+	photos, err := tx.GetUserPhotos(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var removed *models.UserPhoto
+	remaining := make([]*models.UserPhoto, 0, len(photos))
+	for _, p := range photos {
+		if p.ID == photoID {
+			removed = p
+		} else {
+			remaining = append(remaining, p)
 		}
+	}
+	if removed == nil {
+		return errPhotoNotFound
+	}
 
-		ctx := context.TODO()
-		err = s.rabbitRepo.PublishPhoto(ctx, photo)
-		if err != nil {
-			return err
+	// Сервису подбора отправляем фото, которое останется главным: прежнее главное или,
+	// если удаляется оно само или главное не выбрано, первое из оставшихся
+	photo := events.PhotoUpdated{UserID: userID}
+	primaryRemoved := user.PrimaryPhoto != nil && *user.PrimaryPhoto == removed.URL
+	switch {
+	case user.PrimaryPhoto != nil && !primaryRemoved:
+		photo.ImageURL = *user.PrimaryPhoto
+	case len(remaining) > 0:
+		photo.ImageURL = remaining[0].URL
+	}
+
+	if err := tx.RemovePhoto(ctx, userID, photoID); err != nil {
+		return err
+	}
+
+	// Профиль не должен ссылаться на удаленный объект
+	if primaryRemoved {
+		if photo.ImageURL != "" {
+			err = tx.SetPrimaryPhoto(ctx, userID, photo.ImageURL)
+		} else {
+			err = tx.UpdateUser(ctx, userID, map[string]interface{}{"primary_photo": nil})
 		}
-	} else {
-		photos, err := s.storage.GetUserPhotos(userID)
 		if err != nil {
 			return err
 		}
-		index := 0
-		if strings.Contains(photos[index].URL, photoID.String()) && len(photos) > 2 {
-			index++
-		}
-		photo := rabbit.Photo{
-			ID:   userID,
-			Path: photos[index].URL,
-		}
+	}
 
-		ctx := context.TODO()
-		err = s.rabbitRepo.PublishPhoto(ctx, photo)
-		if err != nil {
-			return err
-		}
+	if err := enqueue(ctx, tx, events.TypePhotoRemoved, events.PhotoRemoved{UserID: userID, PhotoID: photoID}); err != nil {
+		return err
 	}
+	return enqueue(ctx, tx, events.TypePhotoUpdated, photo)
+}
 
-	return s.storage.RemovePhoto(userID, photoID)
+// GetUserTags, как и GetUserByID, не показывает теги скрытых и забаненных профилей.
+func (s *UserService) GetUserTags(ctx context.Context, userID uuid.UUID) ([]*models.UserTag, error) {
+	if err := s.ensureVisible(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.storage.GetUserTags(ctx, userID)
 }
 
-func (s *UserService) GetUserTags(userID uuid.UUID) ([]*models.UserTag, error) {
-	return s.storage.GetUserTags(userID)
+func (s *UserService) RemoveUserTag(ctx context.Context, userID, tagID uuid.UUID) error {
+	return s.storage.WithTx(ctx, func(tx storage.UserStorage) error {
+		return s.removeTag(ctx, tx, userID, tagID)
+	})
 }
 
-func (s *UserService) RemoveUserTag(userID, tagID uuid.UUID) error {
-	err := s.storage.RemoveTag(userID, tagID)
-	if err != nil {
+func (s *UserService) removeTag(ctx context.Context, tx storage.UserStorage, userID, tagID uuid.UUID) error {
+	if err := tx.RemoveTag(ctx, userID, tagID); err != nil {
 		return err
 	}
+	return s.enqueueTags(ctx, tx, userID)
+}
 
-	tags_list, err := s.GetUserTags(userID)
+// ensureVisible возвращает errUserNotFound для несуществующего, скрытого или забаненного профиля.
+func (s *UserService) ensureVisible(ctx context.Context, id uuid.UUID) error {
+	user, err := s.storage.GetUserByID(ctx, id)
 	if err != nil {
 		return err
 	}
-	result := ConcatenateTagValues(tags_list)
-	tags := rabbit.Tags{
-		UserID: userID,
-		Tags:   result,
+	if user == nil || user.Hidden || user.BannedAt != nil {
+		return errUserNotFound
 	}
-	ctx := context.TODO()
-	err = s.rabbitRepo.PublishTags(ctx, tags)
-	if err != nil {
-		return err
-	}
-
 	return nil
 }
 
@@ -0,0 +1,445 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/config"
+	"github.com/kerilOvs/profile_sevice/internal/events"
+	"github.com/kerilOvs/profile_sevice/internal/models"
+	"github.com/kerilOvs/profile_sevice/internal/service"
+	"github.com/kerilOvs/profile_sevice/internal/storage/memory"
+	"github.com/kerilOvs/profile_sevice/internal/storage/rabbit"
+)
+
+// harness - UserService поверх хранилища в памяти. flush делает то же, что outbox.Relay:
+// отправляет новые сообщения outbox в Recorder, и возвращает опубликованные события.
+type harness struct {
+	service  *service.UserService
+	storage  *memory.UserMemoryStorage
+	recorder *events.Recorder
+	sent     int
+}
+
+func newHarness(cfg config.EventsConfig) *harness {
+	store := memory.NewUserMemoryStorage()
+	return &harness{
+		service:  service.NewUserService(store, cfg),
+		storage:  store,
+		recorder: events.NewRecorder(0, nil),
+	}
+}
+
+func (h *harness) flush(t *testing.T) []events.Envelope {
+	t.Helper()
+
+	messages := h.storage.OutboxMessages()
+	for _, msg := range messages[h.sent:] {
+		var event events.Envelope
+		if err := json.Unmarshal(msg.Payload, &event); err != nil {
+			t.Fatalf("outbox message %s is not an envelope: %v", msg.ID, err)
+		}
+		if event.Type != msg.Topic {
+			t.Fatalf("outbox topic %q, envelope type %q", msg.Topic, event.Type)
+		}
+		if err := h.recorder.Publish(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h.sent = len(messages)
+
+	published := h.recorder.Events()
+	h.recorder.Reset()
+	return published
+}
+
+func (h *harness) createUser(t *testing.T) uuid.UUID {
+	t.Helper()
+
+	id := uuid.New()
+	if _, err := h.service.CreateUser(context.Background(), id, "Ivan", "Petrov", nil, nil); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	h.flush(t)
+	return id
+}
+
+// expectTypes проверяет типы событий в порядке публикации и общие поля конверта.
+func expectTypes(t *testing.T, published []events.Envelope, types ...string) {
+	t.Helper()
+
+	got := make([]string, len(published))
+	for i, event := range published {
+		got[i] = event.Type
+		if event.Source != events.Source || event.SchemaVersion != events.SchemaVersion(event.Type) {
+			t.Errorf("%s: source %q, schema version %d", event.Type, event.Source, event.SchemaVersion)
+		}
+	}
+	if !slices.Equal(got, types) {
+		t.Fatalf("published %v, want %v", got, types)
+	}
+}
+
+func data[T any](t *testing.T, event events.Envelope) T {
+	t.Helper()
+
+	var result T
+	if err := json.Unmarshal(event.Data, &result); err != nil {
+		t.Fatalf("%s: %v", event.Type, err)
+	}
+	return result
+}
+
+func TestCreateUserEvents(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("legacy anket", func(t *testing.T) {
+		h := newHarness(config.EventsConfig{LegacyAnket: true})
+		id := uuid.New()
+		user, err := h.service.CreateUser(ctx, id, "Ivan", "Petrov", nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		published := h.flush(t)
+		expectTypes(t, published, events.TypeUserCreated, events.TypeAnketUpdated)
+
+		created := data[events.UserCreated](t, published[0])
+		if created.UserID != id || created.Name != "Ivan" || created.Surname != "Petrov" || created.Gender != nil ||
+			!created.CreatedAt.Equal(user.CreatedAt) {
+			t.Errorf("created = %+v", created)
+		}
+
+		anket := data[events.AnketUpdated](t, published[1])
+		want := events.AnketUpdated{UserID: id, Gender: models.GenderFemale, BirthDate: "01/01/2000"}
+		if anket != want {
+			t.Errorf("anket = %+v, want %+v", anket, want)
+		}
+	})
+
+	t.Run("without legacy anket", func(t *testing.T) {
+		h := newHarness(config.EventsConfig{})
+		if _, err := h.service.CreateUser(ctx, uuid.New(), "Ivan", "Petrov", nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		expectTypes(t, h.flush(t), events.TypeUserCreated)
+	})
+
+	t.Run("failed create publishes nothing", func(t *testing.T) {
+		h := newHarness(config.EventsConfig{LegacyAnket: true})
+		id := h.createUser(t)
+		if _, err := h.service.CreateUser(ctx, id, "Ivan", "Petrov", nil, nil); err == nil {
+			t.Fatal("duplicate CreateUser succeeded")
+		}
+		expectTypes(t, h.flush(t))
+	})
+}
+
+func TestTagEvents(t *testing.T) {
+	ctx := context.Background()
+	h := newHarness(config.EventsConfig{LegacyTags: true})
+	id := h.createUser(t)
+
+	category := "hobby"
+	chess, err := h.service.AddUserTag(ctx, id, "chess", &category)
+	if err != nil {
+		t.Fatal(err)
+	}
+	published := h.flush(t)
+	expectTypes(t, published, events.TypeTagsUpdatedV2, events.TypeTagsUpdated)
+
+	v2 := data[events.TagsUpdatedV2](t, published[0])
+	if v2.UserID != id || v2.Version != 1 || len(v2.Tags) != 1 ||
+		v2.Tags[0].ID != chess.ID || v2.Tags[0].Value != "chess" || *v2.Tags[0].Category != category {
+		t.Errorf("tags v2 = %+v", v2)
+	}
+	if legacy := data[events.TagsUpdated](t, published[1]); legacy != (events.TagsUpdated{UserID: id, Tags: "chess"}) {
+		t.Errorf("legacy tags = %+v", legacy)
+	}
+
+	if _, err := h.service.AddUserTag(ctx, id, "go", nil); err != nil {
+		t.Fatal(err)
+	}
+	published = h.flush(t)
+	expectTypes(t, published, events.TypeTagsUpdatedV2, events.TypeTagsUpdated)
+	if v2 := data[events.TagsUpdatedV2](t, published[0]); v2.Version != 2 || len(v2.Tags) != 2 {
+		t.Errorf("tags v2 after second tag = %+v", v2)
+	}
+	if legacy := data[events.TagsUpdated](t, published[1]); legacy.Tags != "chess go" {
+		t.Errorf("legacy tags after second tag = %q", legacy.Tags)
+	}
+
+	if err := h.service.RemoveUserTag(ctx, id, chess.ID); err != nil {
+		t.Fatal(err)
+	}
+	published = h.flush(t)
+	expectTypes(t, published, events.TypeTagsUpdatedV2, events.TypeTagsUpdated)
+	if v2 := data[events.TagsUpdatedV2](t, published[0]); v2.Version != 3 || len(v2.Tags) != 1 || v2.Tags[0].Value != "go" {
+		t.Errorf("tags v2 after remove = %+v", v2)
+	}
+
+	if err := h.service.RemoveUserTag(ctx, id, chess.ID); err == nil {
+		t.Fatal("removing a missing tag succeeded")
+	}
+	expectTypes(t, h.flush(t))
+
+	// Без legacyTags публикуется только v2
+	h = newHarness(config.EventsConfig{})
+	id = h.createUser(t)
+	if _, err := h.service.AddUserTag(ctx, id, "chess", nil); err != nil {
+		t.Fatal(err)
+	}
+	expectTypes(t, h.flush(t), events.TypeTagsUpdatedV2)
+}
+
+// wireBody - тело сообщения, которое relay отправит в RabbitMQ для события.
+func wireBody(t *testing.T, event events.Envelope) string {
+	t.Helper()
+
+	msg, err := rabbit.Message(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(msg.Body)
+}
+
+// В режиме EVENTS_LEGACY_TAGS старая очередь получает ровно то тело, которое отправлял
+// исходный сервис, без конверта.
+func TestLegacyTagsWireBody(t *testing.T) {
+	ctx := context.Background()
+	h := newHarness(config.EventsConfig{LegacyTags: true})
+	id := h.createUser(t)
+
+	if _, err := h.service.AddUserTag(ctx, id, "chess", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.service.AddUserTag(ctx, id, "go", nil); err != nil {
+		t.Fatal(err)
+	}
+	published := h.flush(t)
+	expectTypes(t, published,
+		events.TypeTagsUpdatedV2, events.TypeTagsUpdated, events.TypeTagsUpdatedV2, events.TypeTagsUpdated)
+
+	want := `{"user_id":"` + id.String() + `","tags":"chess go"}`
+	if got := wireBody(t, published[3]); got != want {
+		t.Errorf("tags = %s, want %s", got, want)
+	}
+	// v2 по-прежнему уходит в конверте
+	if got := wireBody(t, published[2]); got == string(published[2].Data) {
+		t.Errorf("tags v2 sent without the envelope: %s", got)
+	}
+}
+
+// В режиме EVENTS_LEGACY_ANKET анкета уходит старой очереди голым DTO: дата в ДД/ММ/ГГГГ,
+// а незаполненные пол и дата заменяются значениями по умолчанию, как в исходном сервисе.
+func TestLegacyAnketWireBody(t *testing.T) {
+	ctx := context.Background()
+	h := newHarness(config.EventsConfig{LegacyAnket: true})
+
+	id := uuid.New()
+	if _, err := h.service.CreateUser(ctx, id, "Ivan", "Petrov", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	published := h.flush(t)
+	expectTypes(t, published, events.TypeUserCreated, events.TypeAnketUpdated)
+	want := `{"user_id":"` + id.String() + `","gender":"FEMALE","birth_date":"01/01/2000"}`
+	if got := wireBody(t, published[1]); got != want {
+		t.Errorf("anket with defaults = %s, want %s", got, want)
+	}
+
+	gender := models.GenderMale
+	birthDate := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+	if err := h.service.UpdateUserProfile(ctx, id, models.UserProfileUpdate{Gender: &gender, BirthDate: &birthDate}); err != nil {
+		t.Fatal(err)
+	}
+	want = `{"user_id":"` + id.String() + `","gender":"MALE","birth_date":"17/05/1990"}`
+	for _, event := range h.flush(t) {
+		if event.Type == events.TypeAnketUpdated {
+			if got := wireBody(t, event); got != want {
+				t.Errorf("anket = %s, want %s", got, want)
+			}
+			return
+		}
+	}
+	t.Fatalf("no %s after the profile update", events.TypeAnketUpdated)
+}
+
+func TestPhotoEvents(t *testing.T) {
+	ctx := context.Background()
+	h := newHarness(config.EventsConfig{})
+	id := h.createUser(t)
+
+	first, err := h.service.AddUserPhoto(ctx, id, "http://minio/photos/first.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	published := h.flush(t)
+	expectTypes(t, published, events.TypePhotoAdded)
+	if added := data[events.PhotoAdded](t, published[0]); added != (events.PhotoAdded{UserID: id, PhotoID: first.ID, URL: first.URL}) {
+		t.Errorf("photo added = %+v", added)
+	}
+
+	second, err := h.service.AddUserPhoto(ctx, id, "http://minio/photos/second.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.flush(t)
+
+	if err := h.service.SetPrimaryPhoto(ctx, id, second.ID); err != nil {
+		t.Fatal(err)
+	}
+	published = h.flush(t)
+	expectTypes(t, published, events.TypePhotoUpdated)
+	if updated := data[events.PhotoUpdated](t, published[0]); updated != (events.PhotoUpdated{UserID: id, ImageURL: second.URL}) {
+		t.Errorf("photo updated = %+v", updated)
+	}
+
+	// Удаление не главного фото: главное остается прежним
+	if err := h.service.RemoveUserPhoto(ctx, id, first.ID); err != nil {
+		t.Fatal(err)
+	}
+	published = h.flush(t)
+	expectTypes(t, published, events.TypePhotoRemoved, events.TypePhotoUpdated)
+	if removed := data[events.PhotoRemoved](t, published[0]); removed != (events.PhotoRemoved{UserID: id, PhotoID: first.ID}) {
+		t.Errorf("photo removed = %+v", removed)
+	}
+	if updated := data[events.PhotoUpdated](t, published[1]); updated != (events.PhotoUpdated{UserID: id, ImageURL: second.URL}) {
+		t.Errorf("photo updated after remove = %+v", updated)
+	}
+
+	if err := h.service.SetPrimaryPhoto(ctx, id, first.ID); err == nil {
+		t.Fatal("SetPrimaryPhoto with a removed photo succeeded")
+	}
+	expectTypes(t, h.flush(t))
+}
+
+func TestDeleteUserEvents(t *testing.T) {
+	ctx := context.Background()
+	h := newHarness(config.EventsConfig{LegacyTags: true, LegacyAnket: true})
+	id := h.createUser(t)
+	if _, err := h.service.AddUserTag(ctx, id, "chess", nil); err != nil {
+		t.Fatal(err)
+	}
+	h.flush(t)
+
+	if err := h.service.DeleteUser(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	published := h.flush(t)
+	expectTypes(t, published, events.TypeUserDeleted)
+	if deleted := data[events.UserDeleted](t, published[0]); deleted.UserID != id {
+		t.Errorf("user deleted = %+v", deleted)
+	}
+
+	if err := h.service.DeleteUser(ctx, id); err == nil {
+		t.Fatal("deleting a missing user succeeded")
+	}
+	expectTypes(t, h.flush(t))
+}
+
+func TestRemovePhotoReassignsPrimary(t *testing.T) {
+	ctx := context.Background()
+	h := newHarness(config.EventsConfig{})
+	id := h.createUser(t)
+
+	var photos []*models.UserPhoto
+	for _, name := range []string{"first", "second", "third"} {
+		photo, err := h.service.AddUserPhoto(ctx, id, "http://minio/photos/"+name+".jpg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		photos = append(photos, photo)
+	}
+	first, second, third := photos[0], photos[1], photos[2]
+	h.flush(t)
+
+	expect := func(t *testing.T, wantPrimary string) {
+		t.Helper()
+
+		published := h.flush(t)
+		expectTypes(t, published, events.TypePhotoRemoved, events.TypePhotoUpdated)
+		if updated := data[events.PhotoUpdated](t, published[1]); updated.ImageURL != wantPrimary {
+			t.Errorf("photo updated = %q, want %q", updated.ImageURL, wantPrimary)
+		}
+
+		user, err := h.service.GetUserByID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var primary string
+		if user.PrimaryPhoto != nil {
+			primary = *user.PrimaryPhoto
+		}
+		if primary != wantPrimary {
+			t.Errorf("primary_photo = %q, want %q", primary, wantPrimary)
+		}
+	}
+
+	if err := h.service.SetPrimaryPhoto(ctx, id, second.ID); err != nil {
+		t.Fatal(err)
+	}
+	h.flush(t)
+
+	// Удалено главное фото: главным становится первое из оставшихся
+	if err := h.service.RemoveUserPhoto(ctx, id, second.ID); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, first.URL)
+
+	if err := h.service.RemoveUserPhoto(ctx, id, first.ID); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, third.URL)
+
+	// Последнее фото: главного больше нет
+	if err := h.service.RemoveUserPhoto(ctx, id, third.ID); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, "")
+}
+
+func TestRemovePhotoWithoutPrimary(t *testing.T) {
+	ctx := context.Background()
+	h := newHarness(config.EventsConfig{})
+	id := h.createUser(t)
+
+	first, err := h.service.AddUserPhoto(ctx, id, "http://minio/photos/first.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := h.service.AddUserPhoto(ctx, id, "http://minio/photos/second.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.flush(t)
+
+	// Главное фото не выбрано: сервис подбора считает главным первое оставшееся
+	if err := h.service.RemoveUserPhoto(ctx, id, first.ID); err != nil {
+		t.Fatal(err)
+	}
+	published := h.flush(t)
+	expectTypes(t, published, events.TypePhotoRemoved, events.TypePhotoUpdated)
+	if updated := data[events.PhotoUpdated](t, published[1]); updated.ImageURL != second.URL {
+		t.Errorf("photo updated = %q, want %q", updated.ImageURL, second.URL)
+	}
+
+	if err := h.service.RemoveUserPhoto(ctx, id, second.ID); err != nil {
+		t.Fatal(err)
+	}
+	published = h.flush(t)
+	if updated := data[events.PhotoUpdated](t, published[1]); updated.ImageURL != "" {
+		t.Errorf("photo updated after the last photo = %q, want empty", updated.ImageURL)
+	}
+
+	user, err := h.service.GetUserByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.PrimaryPhoto != nil {
+		t.Errorf("primary_photo = %q, want unset", *user.PrimaryPhoto)
+	}
+}
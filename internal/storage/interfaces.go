@@ -1,30 +1,76 @@
 package storage
 
 import (
+	"context"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/kerilOvs/profile_sevice/internal/models"
 )
 
 type UserStorage interface {
+	// WithTx выполняет fn в транзакции. Все вызовы через переданный UserStorage
+	// фиксируются вместе или откатываются, если fn вернула ошибку или ctx отменен
+	WithTx(ctx context.Context, fn func(tx UserStorage) error) error
+
 	// Основные операции с пользователем
-	CreateUser(user *models.User) error
-	GetUserByID(id uuid.UUID) (*models.User, error)
-	UpdateUser(id uuid.UUID, updates map[string]interface{}) error
-	DeleteUser(id uuid.UUID) error
+	CreateUser(ctx context.Context, user *models.User) error
+	GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	// GetUsersByIDs отдает найденных пользователей с тегами одним запросом, порядок не гарантирован
+	GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error)
+	UpdateUser(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error
+	DeleteUser(ctx context.Context, id uuid.UUID) error
 
 	// Фото пользователя
-	AddPhoto(photo *models.UserPhoto) error
-	GetUserPhotos(userID uuid.UUID) ([]*models.UserPhoto, error)
-	RemovePhoto(userID, photoID uuid.UUID) error
-	SetPrimaryPhoto(userID uuid.UUID, photoURL string) error
+	AddPhoto(ctx context.Context, photo *models.UserPhoto) error
+	GetUserPhotos(ctx context.Context, userID uuid.UUID) ([]*models.UserPhoto, error)
+	RemovePhoto(ctx context.Context, userID, photoID uuid.UUID) error
+	SetPrimaryPhoto(ctx context.Context, userID uuid.UUID, photoURL string) error
 
 	// Теги пользователя
-	AddTag(tag *models.UserTag) error
-	GetUserTags(userID uuid.UUID) ([]*models.UserTag, error)
-	RemoveTag(userID, tagID uuid.UUID) error
+	AddTag(ctx context.Context, tag *models.UserTag) error
+	GetUserTags(ctx context.Context, userID uuid.UUID) ([]*models.UserTag, error)
+	RemoveTag(ctx context.Context, userID, tagID uuid.UUID) error
+	// BumpTagsVersion увеличивает версию набора тегов пользователя и возвращает новую
+	BumpTagsVersion(ctx context.Context, userID uuid.UUID) (int64, error)
 
 	// Специальные методы
-	UpdateUserAbout(id uuid.UUID, about string) error
-	UpdateUserName(id uuid.UUID, name string) error
-	UpdateUserSurname(id uuid.UUID, surname string) error
+	UpdateUserAbout(ctx context.Context, id uuid.UUID, about string) error
+	UpdateUserName(ctx context.Context, id uuid.UUID, name string) error
+	UpdateUserSurname(ctx context.Context, id uuid.UUID, surname string) error
+
+	// Администрирование
+	ListUsers(ctx context.Context, offset, limit int) ([]*models.User, error)
+	// ListUsersAfter отдает пользователей с id больше after по возрастанию id вместе с тегами.
+	// Нужен для полного обхода с курсором, uuid.Nil - с начала
+	ListUsersAfter(ctx context.Context, after uuid.UUID, limit int) ([]*models.User, error)
+	// SearchUsers отдает пользователей по фильтру от новых к старым вместе с фото и тегами
+	SearchUsers(ctx context.Context, filter UserFilter) ([]*models.User, error)
+	// SearchUsersFullText ищет по имени, фамилии, "о себе" и тегам, лучшие совпадения первыми
+	SearchUsersFullText(ctx context.Context, query string, offset, limit int) ([]*models.UserSearchHit, error)
+	AddAuditRecord(ctx context.Context, record *models.AuditRecord) error
+	ListAuditRecords(ctx context.Context, targetUserID *uuid.UUID, offset, limit int) ([]*models.AuditRecord, error)
+
+	// Outbox: событие сохраняется в той же транзакции, что и изменение
+	AddOutboxMessage(ctx context.Context, msg *models.OutboxMessage) error
+}
+
+type IdempotencyStorage interface {
+	// CreateRecord сохраняет запись, если ее еще нет. created == false, если ключ уже занят
+	CreateRecord(ctx context.Context, record *models.IdempotencyRecord) (created bool, err error)
+	GetRecord(ctx context.Context, owner, key, route string) (*models.IdempotencyRecord, error)
+	CompleteRecord(ctx context.Context, record *models.IdempotencyRecord) error
+	DeleteRecord(ctx context.Context, owner, key, route string) error
+	DeleteRecordsBefore(ctx context.Context, before time.Time) error
+}
+
+type OutboxStorage interface {
+	// ClaimPending забирает до limit сообщений, готовых к отправке, и откладывает
+	// их следующую попытку до now+lease, чтобы их не взял другой экземпляр relay
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxMessage, error)
+	MarkSent(ctx context.Context, id uuid.UUID) error
+	MarkFailed(ctx context.Context, id uuid.UUID, reason string, nextAttempt time.Time) error
+	// Reschedule возвращает невзятые в работу сообщения в очередь без увеличения счетчика попыток
+	Reschedule(ctx context.Context, ids []uuid.UUID, nextAttempt time.Time) error
+	DeleteSentBefore(ctx context.Context, before time.Time) error
 }
@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/kerilOvs/profile_sevice/internal/models"
+)
+
+type idempotencyKey struct {
+	owner, key, route string
+}
+
+// IdempotencyMemoryStorage - storage.IdempotencyStorage в памяти для тестов и локального запуска.
+type IdempotencyMemoryStorage struct {
+	mu      sync.Mutex
+	records map[idempotencyKey]models.IdempotencyRecord
+}
+
+func NewIdempotencyMemoryStorage() *IdempotencyMemoryStorage {
+	return &IdempotencyMemoryStorage{records: make(map[idempotencyKey]models.IdempotencyRecord)}
+}
+
+func (s *IdempotencyMemoryStorage) CreateRecord(ctx context.Context, record *models.IdempotencyRecord) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := idempotencyKey{record.Owner, record.Key, record.Route}
+	if _, ok := s.records[key]; ok {
+		return false, nil
+	}
+	s.records[key] = *record
+	return true, nil
+}
+
+func (s *IdempotencyMemoryStorage) GetRecord(ctx context.Context, owner, key, route string) (*models.IdempotencyRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[idempotencyKey{owner, key, route}]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+func (s *IdempotencyMemoryStorage) CompleteRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := idempotencyKey{record.Owner, record.Key, record.Route}
+	stored, ok := s.records[key]
+	if !ok {
+		return nil
+	}
+	stored.StatusCode = record.StatusCode
+	stored.ContentType = record.ContentType
+	stored.Body = record.Body
+	stored.CompletedAt = record.CompletedAt
+	s.records[key] = stored
+	return nil
+}
+
+func (s *IdempotencyMemoryStorage) DeleteRecord(ctx context.Context, owner, key, route string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, idempotencyKey{owner, key, route})
+	return nil
+}
+
+func (s *IdempotencyMemoryStorage) DeleteRecordsBefore(ctx context.Context, before time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	maps.DeleteFunc(s.records, func(_ idempotencyKey, record models.IdempotencyRecord) bool {
+		return record.CreatedAt.Before(before)
+	})
+	return nil
+}
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/models"
+)
+
+// OutboxMemoryStorage - storage.OutboxStorage над сообщениями, которые UserMemoryStorage
+// сохраняет в AddOutboxMessage. Нужен relay при запуске без базы.
+type OutboxMemoryStorage struct {
+	users *UserMemoryStorage
+}
+
+func NewOutboxMemoryStorage(users *UserMemoryStorage) *OutboxMemoryStorage {
+	return &OutboxMemoryStorage{users: users}
+}
+
+// ClaimPending отдает неотправленные сообщения в порядке записи и откладывает их следующую попытку на lease.
+func (s *OutboxMemoryStorage) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxMessage, error) {
+	unlock, err := s.users.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	now := time.Now()
+	var result []*models.OutboxMessage
+	for i := range s.users.data.outbox {
+		if len(result) == limit {
+			break
+		}
+		msg := &s.users.data.outbox[i]
+		if msg.SentAt != nil || msg.NextAttemptAt.After(now) {
+			continue
+		}
+		msg.NextAttemptAt = now.Add(lease)
+		copied := *msg
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+func (s *OutboxMemoryStorage) MarkSent(ctx context.Context, id uuid.UUID) error {
+	return s.update(ctx, []uuid.UUID{id}, func(msg *models.OutboxMessage) {
+		now := time.Now()
+		msg.SentAt = &now
+	})
+}
+
+func (s *OutboxMemoryStorage) MarkFailed(ctx context.Context, id uuid.UUID, reason string, nextAttempt time.Time) error {
+	return s.update(ctx, []uuid.UUID{id}, func(msg *models.OutboxMessage) {
+		msg.Attempts++
+		msg.LastError = &reason
+		msg.NextAttemptAt = nextAttempt
+	})
+}
+
+func (s *OutboxMemoryStorage) Reschedule(ctx context.Context, ids []uuid.UUID, nextAttempt time.Time) error {
+	return s.update(ctx, ids, func(msg *models.OutboxMessage) {
+		msg.NextAttemptAt = nextAttempt
+	})
+}
+
+func (s *OutboxMemoryStorage) DeleteSentBefore(ctx context.Context, before time.Time) error {
+	unlock, err := s.users.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s.users.data.outbox = slices.DeleteFunc(s.users.data.outbox, func(msg models.OutboxMessage) bool {
+		return msg.SentAt != nil && msg.SentAt.Before(before)
+	})
+	return nil
+}
+
+// update применяет fn к сообщениям с id из ids. Неизвестные id, как и в базе, пропускаются.
+func (s *OutboxMemoryStorage) update(ctx context.Context, ids []uuid.UUID, fn func(msg *models.OutboxMessage)) error {
+	unlock, err := s.users.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for i := range s.users.data.outbox {
+		if slices.Contains(ids, s.users.data.outbox[i].ID) {
+			fn(&s.users.data.outbox[i])
+		}
+	}
+	return nil
+}
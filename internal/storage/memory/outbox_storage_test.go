@@ -0,0 +1,68 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/models"
+	"github.com/kerilOvs/profile_sevice/internal/storage/memory"
+)
+
+func TestOutboxMemoryStorage(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserMemoryStorage()
+	outbox := memory.NewOutboxMemoryStorage(users)
+
+	var ids []uuid.UUID
+	for range 3 {
+		msg := &models.OutboxMessage{ID: uuid.New(), Topic: "profile.user.created", CreatedAt: time.Now()}
+		if err := users.AddOutboxMessage(ctx, msg); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, msg.ID)
+	}
+
+	claimed, err := outbox.ClaimPending(ctx, 2, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 2 || claimed[0].ID != ids[0] || claimed[1].ID != ids[1] {
+		t.Fatalf("claimed = %+v, want the first two messages in order", claimed)
+	}
+
+	// Взятые в работу сообщения не отдаются повторно до истечения lease
+	claimed, err = outbox.ClaimPending(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 1 || claimed[0].ID != ids[2] {
+		t.Fatalf("second claim = %+v, want only the third message", claimed)
+	}
+
+	if err := outbox.MarkSent(ctx, ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.MarkFailed(ctx, ids[1], "broker is down", time.Now().Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.Reschedule(ctx, []uuid.UUID{ids[2]}, time.Now().Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	claimed, err = outbox.ClaimPending(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 2 || claimed[0].ID != ids[1] || claimed[0].Attempts != 1 || claimed[1].Attempts != 0 {
+		t.Fatalf("claim after retry = %+v", claimed)
+	}
+
+	if err := outbox.DeleteSentBefore(ctx, time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if left := users.OutboxMessages(); len(left) != 2 || left[0].ID != ids[1] {
+		t.Fatalf("outbox after cleanup = %+v", left)
+	}
+}
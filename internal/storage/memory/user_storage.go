@@ -0,0 +1,669 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/kerilOvs/profile_sevice/internal/models"
+	"github.com/kerilOvs/profile_sevice/internal/storage"
+)
+
+var (
+	errUserNotFound  = errorsExt.NotFound(errorsExt.CodeUserNotFound, "user not found")
+	errPhotoNotFound = errorsExt.NotFound(errorsExt.CodePhotoNotFound, "photo not found")
+	errTagNotFound   = errorsExt.NotFound(errorsExt.CodeTagNotFound, "tag not found")
+)
+
+// UserMemoryStorage - storage.UserStorage в памяти для тестов и локального запуска.
+// Повторяет семантику UserPostgresStorage: фото и теги удаляются вместе с пользователем,
+// RemovePhoto/RemoveTag находят запись только у ее владельца.
+//
+// Транзакция держит блокировку всего хранилища до завершения, поэтому внутри WithTx
+// нужно работать только через переданный tx.
+type UserMemoryStorage struct {
+	mu   *sync.Mutex
+	data *state
+	// tx - представление внутри WithTx, блокировка уже взята
+	tx bool
+}
+
+// state хранит модели по значению, порядок фото и тегов - порядок добавления.
+type state struct {
+	users  map[uuid.UUID]models.User
+	photos []models.UserPhoto
+	tags   []models.UserTag
+	audit  []models.AuditRecord
+	outbox []models.OutboxMessage
+}
+
+func (s *state) clone() *state {
+	return &state{
+		users:  maps.Clone(s.users),
+		photos: slices.Clone(s.photos),
+		tags:   slices.Clone(s.tags),
+		audit:  slices.Clone(s.audit),
+		outbox: slices.Clone(s.outbox),
+	}
+}
+
+func NewUserMemoryStorage() *UserMemoryStorage {
+	return &UserMemoryStorage{
+		mu:   &sync.Mutex{},
+		data: &state{users: make(map[uuid.UUID]models.User)},
+	}
+}
+
+// begin берет блокировку (вне транзакции) и, как и база, не выполняет запрос с отмененным ctx.
+func (s *UserMemoryStorage) begin(ctx context.Context) (func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if s.tx {
+		return func() {}, nil
+	}
+	s.mu.Lock()
+	return s.mu.Unlock, nil
+}
+
+// WithTx выполняет fn над копией данных и подменяет ими данные при успехе.
+// Вложенный вызов ведет себя как savepoint.
+func (s *UserMemoryStorage) WithTx(ctx context.Context, fn func(tx storage.UserStorage) error) error {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tx := &UserMemoryStorage{mu: s.mu, data: s.data.clone(), tx: true}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	*s.data = *tx.data
+	return nil
+}
+
+func (s *UserMemoryStorage) CreateUser(ctx context.Context, user *models.User) error {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, ok := s.data.users[user.ID]; ok {
+		return errorsExt.Conflict(errorsExt.CodeUserExists, "user already exists")
+	}
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = time.Now()
+	}
+
+	stored := *user
+	stored.Photos, stored.Tags = nil, nil
+	s.data.users[user.ID] = stored
+	return nil
+}
+
+func (s *UserMemoryStorage) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	user, ok := s.data.users[id]
+	if !ok {
+		return nil, nil
+	}
+	return &user, nil
+}
+
+func (s *UserMemoryStorage) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error) {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var users []*models.User
+	for _, id := range ids {
+		user, ok := s.data.users[id]
+		if !ok || slices.ContainsFunc(users, func(u *models.User) bool { return u.ID == id }) {
+			continue
+		}
+		user.Tags = []models.UserTag{}
+		for _, tag := range s.data.userTags(id) {
+			user.Tags = append(user.Tags, *tag)
+		}
+		users = append(users, &user)
+	}
+	return users, nil
+}
+
+func (s *UserMemoryStorage) UpdateUser(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	user, ok := s.data.users[id]
+	if !ok {
+		return errUserNotFound
+	}
+	for column, value := range updates {
+		if err := setColumn(&user, column, value); err != nil {
+			return err
+		}
+	}
+	s.data.users[id] = user
+	return nil
+}
+
+func (s *UserMemoryStorage) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, ok := s.data.users[id]; !ok {
+		return errUserNotFound
+	}
+	delete(s.data.users, id)
+	s.data.photos = slices.DeleteFunc(s.data.photos, func(p models.UserPhoto) bool { return p.UserID == id })
+	s.data.tags = slices.DeleteFunc(s.data.tags, func(t models.UserTag) bool { return t.UserID == id })
+	return nil
+}
+
+func (s *UserMemoryStorage) AddPhoto(ctx context.Context, photo *models.UserPhoto) error {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, ok := s.data.users[photo.UserID]; !ok {
+		return errUserNotFound
+	}
+	if slices.ContainsFunc(s.data.photos, func(p models.UserPhoto) bool { return p.ID == photo.ID }) {
+		return fmt.Errorf("photo %s already exists", photo.ID)
+	}
+	s.data.photos = append(s.data.photos, *photo)
+	return nil
+}
+
+func (s *UserMemoryStorage) GetUserPhotos(ctx context.Context, userID uuid.UUID) ([]*models.UserPhoto, error) {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var photos []*models.UserPhoto
+	for _, photo := range s.data.photos {
+		if photo.UserID == userID {
+			photos = append(photos, &photo)
+		}
+	}
+	return photos, nil
+}
+
+func (s *UserMemoryStorage) RemovePhoto(ctx context.Context, userID, photoID uuid.UUID) error {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	i := slices.IndexFunc(s.data.photos, func(p models.UserPhoto) bool { return p.ID == photoID && p.UserID == userID })
+	if i < 0 {
+		return errPhotoNotFound
+	}
+	s.data.photos = slices.Delete(s.data.photos, i, i+1)
+	return nil
+}
+
+func (s *UserMemoryStorage) SetPrimaryPhoto(ctx context.Context, userID uuid.UUID, photoURL string) error {
+	return s.UpdateUser(ctx, userID, map[string]interface{}{"primary_photo": photoURL})
+}
+
+func (s *UserMemoryStorage) AddTag(ctx context.Context, tag *models.UserTag) error {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, ok := s.data.users[tag.UserID]; !ok {
+		return errUserNotFound
+	}
+	if slices.ContainsFunc(s.data.tags, func(t models.UserTag) bool { return t.ID == tag.ID }) {
+		return fmt.Errorf("tag %s already exists", tag.ID)
+	}
+	s.data.tags = append(s.data.tags, *tag)
+	return nil
+}
+
+func (s *UserMemoryStorage) GetUserTags(ctx context.Context, userID uuid.UUID) ([]*models.UserTag, error) {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return s.data.userTags(userID), nil
+}
+
+func (s *state) userPhotos(userID uuid.UUID) []models.UserPhoto {
+	photos := []models.UserPhoto{}
+	for _, photo := range s.photos {
+		if photo.UserID == userID {
+			photos = append(photos, photo)
+		}
+	}
+	return photos
+}
+
+func (s *state) userTags(userID uuid.UUID) []*models.UserTag {
+	var tags []*models.UserTag
+	for _, tag := range s.tags {
+		if tag.UserID == userID {
+			tags = append(tags, &tag)
+		}
+	}
+	return tags
+}
+
+func (s *UserMemoryStorage) RemoveTag(ctx context.Context, userID, tagID uuid.UUID) error {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	i := slices.IndexFunc(s.data.tags, func(t models.UserTag) bool { return t.ID == tagID && t.UserID == userID })
+	if i < 0 {
+		return errTagNotFound
+	}
+	s.data.tags = slices.Delete(s.data.tags, i, i+1)
+	return nil
+}
+
+func (s *UserMemoryStorage) BumpTagsVersion(ctx context.Context, userID uuid.UUID) (int64, error) {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	user, ok := s.data.users[userID]
+	if !ok {
+		return 0, errUserNotFound
+	}
+	user.TagsVersion++
+	s.data.users[userID] = user
+	return user.TagsVersion, nil
+}
+
+func (s *UserMemoryStorage) UpdateUserAbout(ctx context.Context, id uuid.UUID, about string) error {
+	return s.UpdateUser(ctx, id, map[string]interface{}{"about_myself": about})
+}
+
+func (s *UserMemoryStorage) UpdateUserName(ctx context.Context, id uuid.UUID, name string) error {
+	return s.UpdateUser(ctx, id, map[string]interface{}{"name": name})
+}
+
+func (s *UserMemoryStorage) UpdateUserSurname(ctx context.Context, id uuid.UUID, surname string) error {
+	return s.UpdateUser(ctx, id, map[string]interface{}{"surname": surname})
+}
+
+func (s *UserMemoryStorage) ListUsers(ctx context.Context, offset, limit int) ([]*models.User, error) {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	users := s.data.sortedUsers(func(a, b *models.User) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), bytes.Compare(a.ID[:], b.ID[:]))
+	})
+	return page(users, offset, limit), nil
+}
+
+func (s *UserMemoryStorage) ListUsersAfter(ctx context.Context, after uuid.UUID, limit int) ([]*models.User, error) {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	users := s.data.sortedUsers(func(a, b *models.User) int { return bytes.Compare(a.ID[:], b.ID[:]) })
+	users = slices.DeleteFunc(users, func(u *models.User) bool { return bytes.Compare(u.ID[:], after[:]) <= 0 })
+	users = page(users, 0, limit)
+	for _, user := range users {
+		user.Tags = []models.UserTag{}
+		for _, tag := range s.data.userTags(user.ID) {
+			user.Tags = append(user.Tags, *tag)
+		}
+	}
+	return users, nil
+}
+
+func (s *UserMemoryStorage) SearchUsers(ctx context.Context, filter storage.UserFilter) ([]*models.User, error) {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	users := s.data.sortedUsers(func(a, b *models.User) int { return compareNewest(a.CreatedAt, a.ID, b.CreatedAt, b.ID) })
+	users = slices.DeleteFunc(users, func(u *models.User) bool { return !s.data.matches(u, filter) })
+	users = page(users, 0, filter.Limit)
+	for _, user := range users {
+		user.Photos = s.data.userPhotos(user.ID)
+		user.Tags = []models.UserTag{}
+		for _, tag := range s.data.userTags(user.ID) {
+			user.Tags = append(user.Tags, *tag)
+		}
+	}
+	return users, nil
+}
+
+// Веса полей как в search_vector у Postgres: имя и фамилия, теги, "о себе"
+var fullTextWeights = [3]float64{1, 0.4, 0.2}
+
+// SearchUsersFullText - упрощенный аналог поиска Postgres: слова запроса сравниваются
+// с словами полей без учета регистра, но без стемминга и синтаксиса websearch.
+// Подходят пользователи, у которых есть все слова запроса.
+func (s *UserMemoryStorage) SearchUsersFullText(ctx context.Context, query string, offset, limit int) ([]*models.UserSearchHit, error) {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	terms := make(map[string]bool)
+	for _, word := range words(query) {
+		terms[word] = true
+	}
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	var hits []*models.UserSearchHit
+	for _, user := range s.data.users {
+		var tagValues []string
+		for _, tag := range s.data.userTags(user.ID) {
+			tagValues = append(tagValues, tag.Value)
+		}
+		fields := [3]string{user.Name + " " + user.Surname, strings.Join(tagValues, " ")}
+		if user.AboutMyself != nil {
+			fields[2] = *user.AboutMyself
+		}
+
+		var rank float64
+		found := make(map[string]bool)
+		for i, field := range fields {
+			for _, word := range words(field) {
+				if terms[word] {
+					rank += fullTextWeights[i]
+					found[word] = true
+				}
+			}
+		}
+		if len(found) < len(terms) {
+			continue
+		}
+
+		hits = append(hits, &models.UserSearchHit{
+			User:    user,
+			Rank:    rank,
+			Snippet: highlight(strings.Join(slices.DeleteFunc(fields[:], func(f string) bool { return f == "" }), " "), terms),
+		})
+	}
+
+	slices.SortFunc(hits, func(a, b *models.UserSearchHit) int {
+		return cmp.Or(cmp.Compare(b.Rank, a.Rank), bytes.Compare(a.User.ID[:], b.User.ID[:]))
+	})
+	hits = page(hits, offset, limit)
+	for _, hit := range hits {
+		hit.User.Photos = s.data.userPhotos(hit.User.ID)
+		hit.User.Tags = []models.UserTag{}
+		for _, tag := range s.data.userTags(hit.User.ID) {
+			hit.User.Tags = append(hit.User.Tags, *tag)
+		}
+	}
+	return hits, nil
+}
+
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+}
+
+// highlight экранирует text и оборачивает слова из terms в <b></b>.
+func highlight(text string, terms map[string]bool) string {
+	var b strings.Builder
+	for len(text) > 0 {
+		start := strings.IndexFunc(text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) })
+		if start < 0 {
+			start = len(text)
+		}
+		b.WriteString(html.EscapeString(text[:start]))
+		text = text[start:]
+
+		end := strings.IndexFunc(text, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		if end < 0 {
+			end = len(text)
+		}
+		if word := text[:end]; terms[strings.ToLower(word)] {
+			b.WriteString("<b>" + word + "</b>")
+		} else {
+			b.WriteString(word)
+		}
+		text = text[end:]
+	}
+	return b.String()
+}
+
+func (s *state) matches(user *models.User, filter storage.UserFilter) bool {
+	if filter.Gender != nil && (user.Gender == nil || *user.Gender != *filter.Gender) {
+		return false
+	}
+	if filter.BornFrom != nil && (user.BirthDate == nil || user.BirthDate.Before(*filter.BornFrom)) {
+		return false
+	}
+	if filter.BornTo != nil && (user.BirthDate == nil || !user.BirthDate.Before(*filter.BornTo)) {
+		return false
+	}
+	if filter.JungResult != nil && (user.JungResult == nil || *user.JungResult != *filter.JungResult) {
+		return false
+	}
+	if filter.CreatedFrom != nil && user.CreatedAt.Before(*filter.CreatedFrom) {
+		return false
+	}
+	if filter.CreatedTo != nil && !user.CreatedAt.Before(*filter.CreatedTo) {
+		return false
+	}
+	if !filter.IncludeHidden && (user.Hidden || user.BannedAt != nil) {
+		return false
+	}
+	if filter.After != nil && compareNewest(user.CreatedAt, user.ID, filter.After.CreatedAt, filter.After.ID) <= 0 {
+		return false
+	}
+
+	if len(filter.Tags) == 0 {
+		return true
+	}
+	has := make(map[string]bool)
+	for _, tag := range s.userTags(user.ID) {
+		has[tag.Value] = true
+	}
+	if filter.AllTags {
+		return !slices.ContainsFunc(filter.Tags, func(v string) bool { return !has[v] })
+	}
+	return slices.ContainsFunc(filter.Tags, func(v string) bool { return has[v] })
+}
+
+// compareNewest - порядок SearchUsers: по (created_at, id) по убыванию.
+func compareNewest(aCreated time.Time, aID uuid.UUID, bCreated time.Time, bID uuid.UUID) int {
+	return cmp.Or(bCreated.Compare(aCreated), bytes.Compare(bID[:], aID[:]))
+}
+
+func (s *state) sortedUsers(compare func(a, b *models.User) int) []*models.User {
+	users := make([]*models.User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, &user)
+	}
+	slices.SortFunc(users, compare)
+	return users
+}
+
+func (s *UserMemoryStorage) AddAuditRecord(ctx context.Context, record *models.AuditRecord) error {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+	s.data.audit = append(s.data.audit, *record)
+	return nil
+}
+
+func (s *UserMemoryStorage) ListAuditRecords(ctx context.Context, targetUserID *uuid.UUID, offset, limit int) ([]*models.AuditRecord, error) {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var records []*models.AuditRecord
+	for _, record := range s.data.audit {
+		if targetUserID == nil || record.TargetUserID == *targetUserID {
+			records = append(records, &record)
+		}
+	}
+	slices.SortStableFunc(records, func(a, b *models.AuditRecord) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	return page(records, offset, limit), nil
+}
+
+func (s *UserMemoryStorage) AddOutboxMessage(ctx context.Context, msg *models.OutboxMessage) error {
+	unlock, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if msg.CreatedAt.IsZero() {
+		msg.CreatedAt = time.Now()
+	}
+	s.data.outbox = append(s.data.outbox, *msg)
+	return nil
+}
+
+// OutboxMessages возвращает сообщения, записанные через AddOutboxMessage, в порядке записи.
+func (s *UserMemoryStorage) OutboxMessages() []models.OutboxMessage {
+	unlock, _ := s.begin(context.Background())
+	defer unlock()
+
+	return slices.Clone(s.data.outbox)
+}
+
+// page - аналог OFFSET/LIMIT, limit <= 0 - без ограничения.
+func page[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[max(offset, 0):]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// setColumn применяет к пользователю одно поле из UpdateUser по имени колонки.
+func setColumn(user *models.User, column string, value interface{}) error {
+	var err error
+	switch column {
+	case "name":
+		err = assign(&user.Name, value)
+	case "surname":
+		err = assign(&user.Surname, value)
+	case "about_myself":
+		err = assignPtr(&user.AboutMyself, value)
+	case "gender":
+		if s, ok := value.(string); ok {
+			value = models.UserGender(s)
+		}
+		err = assignPtr(&user.Gender, value)
+	case "birth_date":
+		err = assignPtr(&user.BirthDate, value)
+	case "jung_result":
+		err = assignPtr(&user.JungResult, value)
+	case "jung_last_attempt":
+		err = assignPtr(&user.JungLastAttempt, value)
+	case "primary_photo":
+		err = assignPtr(&user.PrimaryPhoto, value)
+	case "hidden":
+		err = assign(&user.Hidden, value)
+	case "banned_at":
+		err = assignPtr(&user.BannedAt, value)
+	case "ban_reason":
+		err = assignPtr(&user.BanReason, value)
+	case "tags_version":
+		err = assign(&user.TagsVersion, value)
+	default:
+		return fmt.Errorf("unknown users column %q", column)
+	}
+	if err != nil {
+		return fmt.Errorf("column %s: %w", column, err)
+	}
+	return nil
+}
+
+var errColumnType = errors.New("unsupported value type")
+
+func assign[T any](field *T, value interface{}) error {
+	v, ok := value.(T)
+	if !ok {
+		return fmt.Errorf("%w %T", errColumnType, value)
+	}
+	*field = v
+	return nil
+}
+
+// assignPtr принимает значение, указатель на него или nil (NULL).
+func assignPtr[T any](field **T, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*field = nil
+	case T:
+		*field = &v
+	case *T:
+		if v == nil {
+			*field = nil
+		} else {
+			copied := *v
+			*field = &copied
+		}
+	default:
+		return fmt.Errorf("%w %T", errColumnType, value)
+	}
+	return nil
+}
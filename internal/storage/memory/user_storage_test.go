@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/kerilOvs/profile_sevice/internal/storage"
+	"github.com/kerilOvs/profile_sevice/internal/storage/memory"
+	"github.com/kerilOvs/profile_sevice/internal/storage/storagetest"
+)
+
+func TestUserMemoryStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.UserStorage {
+		return memory.NewUserMemoryStorage()
+	})
+}
@@ -0,0 +1,72 @@
+package nats
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+const embeddedStartTimeout = 5 * time.Second
+
+// Embedded - NATS с JetStream внутри процесса.
+type Embedded struct {
+	srv *server.Server
+	// tempDir - каталог JetStream, созданный StartEmbedded. Удаляется в Shutdown
+	tempDir string
+}
+
+// StartEmbedded запускает NATS с JetStream внутри процесса на случайном порту localhost.
+// Заменяет настоящий брокер в локальной разработке и тестах. Пустой storeDir -
+// временный каталог, он удаляется при остановке сервера.
+func StartEmbedded(storeDir string) (*Embedded, error) {
+	embedded := &Embedded{}
+	if storeDir == "" {
+		dir, err := os.MkdirTemp("", "profile-nats-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create nats store dir: %w", err)
+		}
+		embedded.tempDir = dir
+		storeDir = dir
+	}
+
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  storeDir,
+		NoSigs:    true,
+		NoLog:     true,
+	})
+	if err != nil {
+		embedded.removeTempDir()
+		return nil, fmt.Errorf("failed to create embedded nats: %w", err)
+	}
+	embedded.srv = srv
+
+	go srv.Start()
+	if !srv.ReadyForConnections(embeddedStartTimeout) {
+		embedded.Shutdown()
+		return nil, fmt.Errorf("embedded nats did not start in %s", embeddedStartTimeout)
+	}
+	return embedded, nil
+}
+
+// ClientURL - адрес для подключения к серверу.
+func (e *Embedded) ClientURL() string {
+	return e.srv.ClientURL()
+}
+
+// Shutdown останавливает сервер и удаляет временный каталог JetStream.
+func (e *Embedded) Shutdown() {
+	e.srv.Shutdown()
+	e.srv.WaitForShutdown()
+	e.removeTempDir()
+}
+
+func (e *Embedded) removeTempDir() {
+	if e.tempDir != "" {
+		os.RemoveAll(e.tempDir)
+	}
+}
@@ -0,0 +1,137 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kerilOvs/profile_sevice/internal/config"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/kerilOvs/profile_sevice/internal/events"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	msgTimeout     = 5 * time.Second
+	defaultStream  = "PROFILE_EVENTS"
+	streamSubjects = "profile.>"
+	// Окно, в котором JetStream отбрасывает повтор сообщения с тем же Nats-Msg-Id
+	duplicatesWindow = 10 * time.Minute
+)
+
+// Repo публикует события в JetStream stream. Subject равен типу события, тело -
+// тот же конверт events.Envelope, что и в RabbitMQ.
+type Repo struct {
+	conn     *nats.Conn
+	js       jetstream.JetStream
+	embedded *Embedded
+}
+
+// New подключается к NATS и создает (или обновляет) stream для событий профиля.
+// При cfg.Embedded сервер NATS запускается внутри процесса.
+func New(ctx context.Context, cfg config.NATSConfig, log *slog.Logger) (*Repo, error) {
+	log = log.WithGroup("nats")
+	repo := &Repo{}
+
+	url := cfg.Url
+	if cfg.Embedded {
+		srv, err := StartEmbedded(cfg.StoreDir)
+		if err != nil {
+			return nil, err
+		}
+		repo.embedded = srv
+		url = srv.ClientURL()
+		log.Warn("Using embedded NATS server", slog.String("url", url))
+	}
+
+	conn, err := nats.Connect(url,
+		nats.Name("profile-service"),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			// err == nil при штатном Close
+			if err != nil {
+				log.Warn("nats connection lost, reconnecting", slog.Any("reason", err))
+			}
+		}),
+		nats.ReconnectHandler(func(*nats.Conn) {
+			log.Info("nats reconnected")
+		}),
+	)
+	if err != nil {
+		repo.shutdownEmbedded()
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	repo.conn = conn
+
+	repo.js, err = jetstream.New(conn)
+	if err != nil {
+		repo.Close()
+		return nil, fmt.Errorf("failed to create jetstream context: %w", err)
+	}
+
+	stream := cfg.Stream
+	if stream == "" {
+		stream = defaultStream
+	}
+	_, err = repo.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:       stream,
+		Subjects:   []string{streamSubjects},
+		Storage:    jetstream.FileStorage,
+		Duplicates: duplicatesWindow,
+	})
+	if err != nil {
+		repo.Close()
+		return nil, fmt.Errorf("failed to create stream %s: %w", stream, err)
+	}
+
+	return repo, nil
+}
+
+// Publish отправляет событие и ждет подтверждения JetStream. id события передается
+// как Nats-Msg-Id, поэтому повторная отправка из outbox не создает дубликат.
+func (r *Repo) Publish(ctx context.Context, event events.Envelope) error {
+	body, contentType, err := events.Body(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, msgTimeout)
+	defer cancel()
+
+	msg := nats.NewMsg(event.Type)
+	msg.Data = body
+	msg.Header.Set("Content-Type", contentType)
+	if event.CorrelationID != "" {
+		msg.Header.Set("Correlation-Id", event.CorrelationID)
+	}
+
+	if _, err := r.js.PublishMsg(ctx, msg, jetstream.WithMsgID(event.ID)); err != nil {
+		return errorsExt.Upstream(errorsExt.CodeBrokerUnavailable, "failed to publish "+event.Type, err)
+	}
+	return nil
+}
+
+// Ready - проверка готовности для health check.
+func (r *Repo) Ready(context.Context) error {
+	if status := r.conn.Status(); status != nats.CONNECTED {
+		return fmt.Errorf("nats is not connected: %s", status)
+	}
+	return nil
+}
+
+func (r *Repo) Close() error {
+	if r.conn != nil {
+		r.conn.Close()
+	}
+	r.shutdownEmbedded()
+	return nil
+}
+
+func (r *Repo) shutdownEmbedded() {
+	if r.embedded != nil {
+		r.embedded.Shutdown()
+		r.embedded = nil
+	}
+}
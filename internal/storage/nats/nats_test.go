@@ -0,0 +1,155 @@
+package nats
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/config"
+	"github.com/kerilOvs/profile_sevice/internal/events"
+	"github.com/kerilOvs/profile_sevice/internal/models"
+	"github.com/kerilOvs/profile_sevice/internal/service"
+	"github.com/kerilOvs/profile_sevice/internal/storage/memory"
+	"github.com/kerilOvs/profile_sevice/internal/storage/rabbit"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// profileEvents проводит профиль через UserService и возвращает события из outbox
+// в порядке записи: именно их relay отправляет в брокер.
+func profileEvents(t *testing.T) []events.Envelope {
+	t.Helper()
+
+	ctx := context.Background()
+	store := memory.NewUserMemoryStorage()
+	users := service.NewUserService(store, config.EventsConfig{LegacyTags: true, LegacyAnket: true})
+
+	id := uuid.New()
+	if _, err := users.CreateUser(ctx, id, "Ivan", "Petrov", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	category := "hobby"
+	if _, err := users.AddUserTag(ctx, id, "chess", &category); err != nil {
+		t.Fatal(err)
+	}
+	photo, err := users.AddUserPhoto(ctx, id, "http://minio/photos/first.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := users.SetPrimaryPhoto(ctx, id, photo.ID); err != nil {
+		t.Fatal(err)
+	}
+	gender := models.GenderMale
+	birthDate := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+	if err := users.UpdateUserProfile(ctx, id, models.UserProfileUpdate{Gender: &gender, BirthDate: &birthDate}); err != nil {
+		t.Fatal(err)
+	}
+
+	var result []events.Envelope
+	for _, msg := range store.OutboxMessages() {
+		var event events.Envelope
+		if err := json.Unmarshal(msg.Payload, &event); err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, event)
+	}
+	return result
+}
+
+func TestPublishMatchesRabbitMessages(t *testing.T) {
+	ctx := context.Background()
+	repo, err := New(ctx, config.NATSConfig{Embedded: true}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	storeDir := repo.embedded.tempDir
+
+	sent := profileEvents(t)
+	for _, event := range sent {
+		if err := repo.Publish(ctx, event); err != nil {
+			t.Fatalf("publish %s: %v", event.Type, err)
+		}
+	}
+	// Повторная отправка из outbox отбрасывается по Nats-Msg-Id
+	if err := repo.Publish(ctx, sent[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	consumer, err := repo.js.OrderedConsumer(ctx, defaultStream, jetstream.OrderedConsumerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch, err := consumer.Fetch(len(sent)+1, jetstream.FetchMaxWait(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received []jetstream.Msg
+	for msg := range batch.Messages() {
+		received = append(received, msg)
+	}
+	if len(received) != len(sent) {
+		t.Fatalf("received %d messages, want %d", len(received), len(sent))
+	}
+
+	types := map[string]bool{}
+	for i, msg := range received {
+		want, err := rabbit.Message(sent[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Subject равен routing key в RabbitMQ, тело - тот же конверт
+		if msg.Subject() != sent[i].Type {
+			t.Errorf("message %d: subject %q, rabbit routing key %q", i, msg.Subject(), sent[i].Type)
+		}
+		if !bytes.Equal(msg.Data(), want.Body) {
+			t.Errorf("%s: body differs from rabbit\nnats:   %s\nrabbit: %s", sent[i].Type, msg.Data(), want.Body)
+		}
+		if got := msg.Headers().Get("Content-Type"); got != want.ContentType {
+			t.Errorf("%s: content type %q, rabbit %q", sent[i].Type, got, want.ContentType)
+		}
+		if got := msg.Headers().Get(nats.MsgIdHdr); got != want.MessageId {
+			t.Errorf("%s: message id %q, rabbit %q", sent[i].Type, got, want.MessageId)
+		}
+		types[msg.Subject()] = true
+	}
+
+	for _, eventType := range []string{
+		events.TypeTagsUpdated, events.TypeTagsUpdatedV2,
+		events.TypePhotoAdded, events.TypePhotoUpdated,
+		events.TypeAnketUpdated, events.TypeAnketUpdatedV2,
+	} {
+		if !types[eventType] {
+			t.Errorf("no %s event published", eventType)
+		}
+	}
+
+	if err := repo.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(storeDir); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("embedded store dir %s was not removed: %v", storeDir, err)
+	}
+}
+
+func TestStartEmbeddedKeepsConfiguredStoreDir(t *testing.T) {
+	dir := t.TempDir()
+	srv, err := StartEmbedded(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Shutdown()
+
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("configured store dir removed: %v", err)
+	}
+}
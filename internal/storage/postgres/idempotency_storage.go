@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kerilOvs/profile_sevice/internal/models"
+)
+
+type IdempotencyPostgresStorage struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyPostgresStorage(db *gorm.DB) *IdempotencyPostgresStorage {
+	return &IdempotencyPostgresStorage{db: db}
+}
+
+func (s *IdempotencyPostgresStorage) CreateRecord(ctx context.Context, record *models.IdempotencyRecord) (bool, error) {
+	res := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (s *IdempotencyPostgresStorage) GetRecord(ctx context.Context, owner, key, route string) (*models.IdempotencyRecord, error) {
+	var record models.IdempotencyRecord
+	err := s.db.WithContext(ctx).First(&record, "owner = ? AND key = ? AND route = ?", owner, key, route).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *IdempotencyPostgresStorage) CompleteRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	return s.db.WithContext(ctx).Model(&models.IdempotencyRecord{}).
+		Where("owner = ? AND key = ? AND route = ?", record.Owner, record.Key, record.Route).
+		Updates(map[string]interface{}{
+			"status_code":  record.StatusCode,
+			"content_type": record.ContentType,
+			"body":         record.Body,
+			"completed_at": record.CompletedAt,
+		}).Error
+}
+
+func (s *IdempotencyPostgresStorage) DeleteRecord(ctx context.Context, owner, key, route string) error {
+	return s.db.WithContext(ctx).Where("owner = ? AND key = ? AND route = ?", owner, key, route).
+		Delete(&models.IdempotencyRecord{}).Error
+}
+
+func (s *IdempotencyPostgresStorage) DeleteRecordsBefore(ctx context.Context, before time.Time) error {
+	return s.db.WithContext(ctx).Where("created_at < ?", before).Delete(&models.IdempotencyRecord{}).Error
+}
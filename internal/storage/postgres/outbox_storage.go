@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kerilOvs/profile_sevice/internal/models"
+)
+
+type OutboxPostgresStorage struct {
+	db *gorm.DB
+}
+
+func NewOutboxPostgresStorage(db *gorm.DB) *OutboxPostgresStorage {
+	return &OutboxPostgresStorage{db: db}
+}
+
+// ClaimPending использует SKIP LOCKED, поэтому несколько экземпляров relay не берут одни и те же сообщения.
+func (s *OutboxPostgresStorage) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboxMessage, error) {
+	now := time.Now()
+
+	var messages []*models.OutboxMessage
+	err := s.db.WithContext(ctx).Raw(`
+		UPDATE outbox_messages SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM outbox_messages
+			WHERE sent_at IS NULL AND next_attempt_at <= ?
+			ORDER BY created_at, id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, now.Add(lease), now, limit).Scan(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING не сохраняет порядок подзапроса
+	slices.SortFunc(messages, func(a, b *models.OutboxMessage) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return slices.Compare(a.ID[:], b.ID[:])
+	})
+
+	return messages, nil
+}
+
+func (s *OutboxPostgresStorage) MarkSent(ctx context.Context, id uuid.UUID) error {
+	return s.db.WithContext(ctx).Model(&models.OutboxMessage{}).Where("id = ?", id).Update("sent_at", time.Now()).Error
+}
+
+func (s *OutboxPostgresStorage) MarkFailed(ctx context.Context, id uuid.UUID, reason string, nextAttempt time.Time) error {
+	return s.db.WithContext(ctx).Model(&models.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      reason,
+		"next_attempt_at": nextAttempt,
+	}).Error
+}
+
+func (s *OutboxPostgresStorage) Reschedule(ctx context.Context, ids []uuid.UUID, nextAttempt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.db.WithContext(ctx).Model(&models.OutboxMessage{}).Where("id IN ?", ids).Update("next_attempt_at", nextAttempt).Error
+}
+
+func (s *OutboxPostgresStorage) DeleteSentBefore(ctx context.Context, before time.Time) error {
+	return s.db.WithContext(ctx).Where("sent_at < ?", before).Delete(&models.OutboxMessage{}).Error
+}
@@ -1,12 +1,22 @@
 package postgres
 
 import (
+	"context"
 	"errors"
+	"slices"
 
 	"gorm.io/gorm"
 
 	"github.com/google/uuid"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
 	"github.com/kerilOvs/profile_sevice/internal/models"
+	"github.com/kerilOvs/profile_sevice/internal/storage"
+)
+
+var (
+	errUserNotFound  = errorsExt.NotFound(errorsExt.CodeUserNotFound, "user not found")
+	errPhotoNotFound = errorsExt.NotFound(errorsExt.CodePhotoNotFound, "photo not found")
+	errTagNotFound   = errorsExt.NotFound(errorsExt.CodeTagNotFound, "tag not found")
 )
 
 type UserPostgresStorage struct {
@@ -17,13 +27,23 @@ func NewUserPostgresStorage(db *gorm.DB) *UserPostgresStorage {
 	return &UserPostgresStorage{db: db}
 }
 
-func (s *UserPostgresStorage) CreateUser(user *models.User) error {
-	return s.db.Create(user).Error
+func (s *UserPostgresStorage) WithTx(ctx context.Context, fn func(tx storage.UserStorage) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&UserPostgresStorage{db: tx})
+	})
+}
+
+func (s *UserPostgresStorage) CreateUser(ctx context.Context, user *models.User) error {
+	err := s.db.WithContext(ctx).Create(user).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return errorsExt.Conflict(errorsExt.CodeUserExists, "user already exists")
+	}
+	return err
 }
 
-func (s *UserPostgresStorage) GetUserByID(id uuid.UUID) (*models.User, error) {
+func (s *UserPostgresStorage) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	var user models.User
-	if err := s.db.First(&user, "id = ?", id).Error; err != nil {
+	if err := s.db.WithContext(ctx).First(&user, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -32,54 +52,235 @@ func (s *UserPostgresStorage) GetUserByID(id uuid.UUID) (*models.User, error) {
 	return &user, nil
 }
 
-func (s *UserPostgresStorage) UpdateUser(id uuid.UUID, updates map[string]interface{}) error {
-	return s.db.Model(&models.User{}).Where("id = ?", id).Updates(updates).Error
+func (s *UserPostgresStorage) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error) {
+	var users []*models.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := s.db.WithContext(ctx).Preload("Tags").Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
+func (s *UserPostgresStorage) UpdateUser(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
+	return affected(s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Updates(updates), errUserNotFound)
 }
 
-func (s *UserPostgresStorage) DeleteUser(id uuid.UUID) error {
-	return s.db.Where("id = ?", id).Delete(&models.User{}).Error
+func (s *UserPostgresStorage) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	return affected(s.db.WithContext(ctx).Where("id = ?", id).Delete(&models.User{}), errUserNotFound)
 }
 
-func (s *UserPostgresStorage) AddPhoto(photo *models.UserPhoto) error {
-	return s.db.Create(photo).Error
+func (s *UserPostgresStorage) AddPhoto(ctx context.Context, photo *models.UserPhoto) error {
+	return ownerExists(s.db.WithContext(ctx).Create(photo).Error)
 }
 
-func (s *UserPostgresStorage) GetUserPhotos(userID uuid.UUID) ([]*models.UserPhoto, error) {
+func (s *UserPostgresStorage) GetUserPhotos(ctx context.Context, userID uuid.UUID) ([]*models.UserPhoto, error) {
 	var photos []*models.UserPhoto
-	err := s.db.Where("user_id = ?", userID).Find(&photos).Error
+	err := s.db.WithContext(ctx).Where("user_id = ?", userID).Find(&photos).Error
 	return photos, err
 }
 
-func (s *UserPostgresStorage) RemovePhoto(userID, photoID uuid.UUID) error {
-	return s.db.Where("id = ? AND user_id = ?", photoID, userID).Delete(&models.UserPhoto{}).Error
+func (s *UserPostgresStorage) RemovePhoto(ctx context.Context, userID, photoID uuid.UUID) error {
+	return affected(s.db.WithContext(ctx).Where("id = ? AND user_id = ?", photoID, userID).Delete(&models.UserPhoto{}), errPhotoNotFound)
 }
 
-func (s *UserPostgresStorage) SetPrimaryPhoto(userID uuid.UUID, photoURL string) error {
-	return s.db.Model(&models.User{}).Where("id = ?", userID).Update("primary_photo", photoURL).Error
+func (s *UserPostgresStorage) SetPrimaryPhoto(ctx context.Context, userID uuid.UUID, photoURL string) error {
+	return affected(s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Update("primary_photo", photoURL), errUserNotFound)
 }
 
-func (s *UserPostgresStorage) AddTag(tag *models.UserTag) error {
-	return s.db.Create(tag).Error
+func (s *UserPostgresStorage) AddTag(ctx context.Context, tag *models.UserTag) error {
+	return ownerExists(s.db.WithContext(ctx).Create(tag).Error)
 }
 
-func (s *UserPostgresStorage) GetUserTags(userID uuid.UUID) ([]*models.UserTag, error) {
+func (s *UserPostgresStorage) GetUserTags(ctx context.Context, userID uuid.UUID) ([]*models.UserTag, error) {
 	var tags []*models.UserTag
-	err := s.db.Where("user_id = ?", userID).Find(&tags).Error
+	err := s.db.WithContext(ctx).Where("user_id = ?", userID).Find(&tags).Error
 	return tags, err
 }
 
-func (s *UserPostgresStorage) RemoveTag(userID, tagID uuid.UUID) error {
-	return s.db.Where("id = ? AND user_id = ?", tagID, userID).Delete(&models.UserTag{}).Error
+func (s *UserPostgresStorage) BumpTagsVersion(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var version int64
+	res := s.db.WithContext(ctx).Raw("UPDATE users SET tags_version = tags_version + 1 WHERE id = ? RETURNING tags_version", userID).Scan(&version)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return 0, errUserNotFound
+	}
+	return version, nil
+}
+
+func (s *UserPostgresStorage) RemoveTag(ctx context.Context, userID, tagID uuid.UUID) error {
+	return affected(s.db.WithContext(ctx).Where("id = ? AND user_id = ?", tagID, userID).Delete(&models.UserTag{}), errTagNotFound)
+}
+
+func (s *UserPostgresStorage) UpdateUserAbout(ctx context.Context, id uuid.UUID, about string) error {
+	return affected(s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Update("about_myself", about), errUserNotFound)
+}
+
+func (s *UserPostgresStorage) UpdateUserName(ctx context.Context, id uuid.UUID, name string) error {
+	return affected(s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Update("name", name), errUserNotFound)
 }
 
-func (s *UserPostgresStorage) UpdateUserAbout(id uuid.UUID, about string) error {
-	return s.db.Model(&models.User{}).Where("id = ?", id).Update("about_myself", about).Error
+func (s *UserPostgresStorage) UpdateUserSurname(ctx context.Context, id uuid.UUID, surname string) error {
+	return affected(s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Update("surname", surname), errUserNotFound)
 }
 
-func (s *UserPostgresStorage) UpdateUserName(id uuid.UUID, name string) error {
-	return s.db.Model(&models.User{}).Where("id = ?", id).Update("name", name).Error
+func (s *UserPostgresStorage) ListUsers(ctx context.Context, offset, limit int) ([]*models.User, error) {
+	var users []*models.User
+	err := s.db.WithContext(ctx).Order("created_at DESC, id").Offset(offset).Limit(limit).Find(&users).Error
+	return users, err
 }
 
-func (s *UserPostgresStorage) UpdateUserSurname(id uuid.UUID, surname string) error {
-	return s.db.Model(&models.User{}).Where("id = ?", id).Update("surname", surname).Error
+func (s *UserPostgresStorage) ListUsersAfter(ctx context.Context, after uuid.UUID, limit int) ([]*models.User, error) {
+	var users []*models.User
+	err := s.db.WithContext(ctx).Preload("Tags").Where("id > ?", after).Order("id").Limit(limit).Find(&users).Error
+	return users, err
+}
+
+func (s *UserPostgresStorage) SearchUsers(ctx context.Context, filter storage.UserFilter) ([]*models.User, error) {
+	query := s.db.WithContext(ctx).Preload("Photos").Preload("Tags")
+	if filter.Gender != nil {
+		query = query.Where("gender = ?", *filter.Gender)
+	}
+	if filter.BornFrom != nil {
+		query = query.Where("birth_date >= ?", *filter.BornFrom)
+	}
+	if filter.BornTo != nil {
+		query = query.Where("birth_date < ?", *filter.BornTo)
+	}
+	if filter.JungResult != nil {
+		query = query.Where("jung_result = ?", *filter.JungResult)
+	}
+	if tags := distinct(filter.Tags); len(tags) > 0 {
+		if filter.AllTags {
+			query = query.Where("(SELECT count(DISTINCT t.value) FROM user_tags t WHERE t.user_id = users.id AND t.value IN ?) = ?", tags, len(tags))
+		} else {
+			query = query.Where("EXISTS (SELECT 1 FROM user_tags t WHERE t.user_id = users.id AND t.value IN ?)", tags)
+		}
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+	if !filter.IncludeHidden {
+		// Условие совпадает с частичным индексом idx_users_visible_created_at_id
+		query = query.Where("hidden IS NOT TRUE AND banned_at IS NULL")
+	}
+	if filter.After != nil {
+		query = query.Where("(created_at, id) < (?, ?)", filter.After.CreatedAt, filter.After.ID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var users []*models.User
+	err := query.Order("created_at DESC, id DESC").Find(&users).Error
+	return users, err
+}
+
+// fullTextQuery ранжирует по search_vector (миграция 0004) и строит сниппет только
+// для строк страницы. Текст экранируется до ts_headline, чтобы единственной разметкой были <b></b>.
+const fullTextQuery = `
+WITH q AS (
+    SELECT websearch_to_tsquery('russian', @query) || websearch_to_tsquery('english', @query) AS query
+), page AS (
+    SELECT u.id, u.name, u.surname, u.about_myself, ts_rank_cd(u.search_vector, q.query) AS rank
+    FROM users u, q
+    WHERE u.search_vector @@ q.query
+    ORDER BY rank DESC, u.id
+    OFFSET @offset LIMIT @limit
+)
+SELECT page.id, page.rank,
+       ts_headline('russian',
+           replace(replace(replace(
+               concat_ws(' ', page.name, page.surname,
+                   (SELECT string_agg(t.value, ' ') FROM user_tags t WHERE t.user_id = page.id),
+                   page.about_myself),
+               '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+           q.query,
+           'StartSel=<b>, StopSel=</b>, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" ... "') AS snippet
+FROM page, q
+ORDER BY page.rank DESC, page.id`
+
+func (s *UserPostgresStorage) SearchUsersFullText(ctx context.Context, query string, offset, limit int) ([]*models.UserSearchHit, error) {
+	var rows []struct {
+		ID      uuid.UUID
+		Rank    float64
+		Snippet string
+	}
+	err := s.db.WithContext(ctx).Raw(fullTextQuery, map[string]interface{}{
+		"query":  query,
+		"offset": offset,
+		"limit":  limit,
+	}).Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	var users []*models.User
+	if err := s.db.WithContext(ctx).Preload("Photos").Preload("Tags").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	hits := make([]*models.UserSearchHit, 0, len(rows))
+	for _, row := range rows {
+		// Пользователя могли удалить между запросами
+		if user, ok := byID[row.ID]; ok {
+			hits = append(hits, &models.UserSearchHit{User: *user, Rank: row.Rank, Snippet: row.Snippet})
+		}
+	}
+	return hits, nil
+}
+
+func (s *UserPostgresStorage) AddAuditRecord(ctx context.Context, record *models.AuditRecord) error {
+	return s.db.WithContext(ctx).Create(record).Error
+}
+
+func (s *UserPostgresStorage) ListAuditRecords(ctx context.Context, targetUserID *uuid.UUID, offset, limit int) ([]*models.AuditRecord, error) {
+	var records []*models.AuditRecord
+	query := s.db.WithContext(ctx).Order("created_at DESC")
+	if targetUserID != nil {
+		query = query.Where("target_user_id = ?", *targetUserID)
+	}
+	err := query.Offset(offset).Limit(limit).Find(&records).Error
+	return records, err
+}
+
+func (s *UserPostgresStorage) AddOutboxMessage(ctx context.Context, msg *models.OutboxMessage) error {
+	return s.db.WithContext(ctx).Create(msg).Error
+}
+
+func distinct(values []string) []string {
+	values = slices.Clone(values)
+	slices.Sort(values)
+	return slices.Compact(values)
+}
+
+// affected возвращает notFound, если запрос не затронул ни одной строки.
+func affected(res *gorm.DB, notFound error) error {
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// ownerExists переводит нарушение внешнего ключа на users в отсутствие пользователя.
+func ownerExists(err error) error {
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		return errUserNotFound
+	}
+	return err
 }
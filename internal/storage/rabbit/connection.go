@@ -0,0 +1,273 @@
+package rabbit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	defaultReconnectDelay    = 500 * time.Millisecond
+	defaultReconnectMaxDelay = 30 * time.Second
+)
+
+var (
+	ErrNotConnected = errors.New("rabbit is not connected")
+	ErrNacked       = errors.New("broker did not acknowledge the message")
+	ErrUnroutable   = errors.New("message was returned as unroutable")
+)
+
+// State - состояние соединения с брокером.
+type State int32
+
+const (
+	StateConnecting State = iota
+	StateConnected
+	StateReconnecting
+	StateClosed
+)
+
+func (s State) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// connection держит соединение и канал к RabbitMQ. Следит за NotifyClose и переподключается
+// с экспоненциальной задержкой, после чего заново вызывает setup (объявление очередей).
+type connection struct {
+	url      string
+	setup    func(*amqp.Channel) error
+	minDelay time.Duration
+	maxDelay time.Duration
+	log      *slog.Logger
+
+	mu      sync.RWMutex
+	conn    *amqp.Connection
+	channel *amqp.Channel
+	returns chan amqp.Return
+
+	// Публикации идут по одной: так возврат (basic.return) однозначно относится к текущему сообщению
+	publishMu sync.Mutex
+
+	state atomic.Int32
+	done  chan struct{}
+	once  sync.Once
+
+	// redial и after подменяются в тестах переподключения
+	redial func() error
+	after  func(time.Duration) <-chan time.Time
+}
+
+func newConnection(url string, setup func(*amqp.Channel) error, minDelay, maxDelay time.Duration, log *slog.Logger) *connection {
+	if minDelay <= 0 {
+		minDelay = defaultReconnectDelay
+	}
+	if maxDelay < minDelay {
+		maxDelay = max(defaultReconnectMaxDelay, minDelay)
+	}
+
+	c := &connection{
+		url:      url,
+		setup:    setup,
+		minDelay: minDelay,
+		maxDelay: maxDelay,
+		log:      log,
+		done:     make(chan struct{}),
+		after:    time.After,
+	}
+	c.redial = c.dial
+	c.state.Store(int32(StateConnecting))
+	return c
+}
+
+// connect выполняет первое подключение и запускает наблюдение за ним.
+func (c *connection) connect() error {
+	if err := c.dial(); err != nil {
+		return err
+	}
+	c.state.Store(int32(StateConnected))
+	return nil
+}
+
+func (c *connection) dial() error {
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+
+	// Если упал только канал, соединение переиспользуем
+	if conn == nil || conn.IsClosed() {
+		var err error
+		conn, err = amqp.Dial(c.url)
+		if err != nil {
+			return fmt.Errorf("failed to connect to Rabbit: %w", err)
+		}
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to open a channel: %w", err)
+	}
+
+	if err := c.setup(channel); err != nil {
+		channel.Close()
+		return err
+	}
+
+	// Publisher confirms: брокер подтверждает каждое сообщение после сохранения
+	if err := channel.Confirm(false); err != nil {
+		channel.Close()
+		return fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+	returns := channel.NotifyReturn(make(chan amqp.Return, 16))
+
+	c.mu.Lock()
+	c.conn = conn
+	c.channel = channel
+	c.returns = returns
+	c.mu.Unlock()
+
+	go c.watch(conn, channel)
+	return nil
+}
+
+func (c *connection) watch(conn *amqp.Connection, channel *amqp.Channel) {
+	connClosed := conn.NotifyClose(make(chan *amqp.Error, 1))
+	channelClosed := channel.NotifyClose(make(chan *amqp.Error, 1))
+
+	var reason *amqp.Error
+	select {
+	case <-c.done:
+		return
+	case reason = <-connClosed:
+	case reason = <-channelClosed:
+	}
+
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	c.state.Store(int32(StateReconnecting))
+	c.log.Warn("rabbit connection lost, reconnecting", slog.Any("reason", reason))
+	c.reconnect()
+}
+
+func (c *connection) reconnect() {
+	delay := c.minDelay
+	for attempt := 1; ; attempt++ {
+		// Небольшой разброс, чтобы экземпляры сервиса не переподключались одновременно
+		wait := delay/2 + rand.N(delay/2+1)
+		select {
+		case <-c.done:
+			return
+		case <-c.after(wait):
+		}
+
+		err := c.redial()
+		if err == nil {
+			c.state.Store(int32(StateConnected))
+			c.log.Info("rabbit reconnected", slog.Int("attempt", attempt))
+			return
+		}
+
+		c.log.Warn("failed to reconnect to rabbit", slog.Int("attempt", attempt), slog.Any("error", err))
+		delay = min(delay*2, c.maxDelay)
+	}
+}
+
+// publish отправляет сообщение с флагом mandatory и ждет подтверждения брокера.
+// Сообщение, которое не попало ни в одну очередь, возвращается как ErrUnroutable.
+func (c *connection) publish(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	if c.State() != StateConnected {
+		return ErrNotConnected
+	}
+
+	c.mu.RLock()
+	channel, returns := c.channel, c.returns
+	c.mu.RUnlock()
+	if channel == nil || channel.IsClosed() {
+		return ErrNotConnected
+	}
+
+	c.publishMu.Lock()
+	defer c.publishMu.Unlock()
+
+	confirm, err := channel.PublishWithDeferredConfirmWithContext(ctx, exchange, key, true, false, msg)
+	if err != nil {
+		return err
+	}
+
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Брокер присылает basic.return раньше подтверждения, поэтому возврат уже в канале.
+	// Чужие возвраты остаются от сообщений, ожидание которых прервано по таймауту
+drain:
+	for {
+		select {
+		case ret := <-returns:
+			if ret.MessageId == msg.MessageId {
+				return fmt.Errorf("%w: %s %s", ErrUnroutable, ret.ReplyText, ret.RoutingKey)
+			}
+		default:
+			break drain
+		}
+	}
+
+	if !acked {
+		return ErrNacked
+	}
+	return nil
+}
+
+func (c *connection) State() State {
+	return State(c.state.Load())
+}
+
+// Ready сообщает, есть ли сейчас соединение с брокером.
+func (c *connection) Ready(context.Context) error {
+	if state := c.State(); state != StateConnected {
+		return fmt.Errorf("%w: %s", ErrNotConnected, state)
+	}
+	return nil
+}
+
+func (c *connection) Close() error {
+	c.once.Do(func() { close(c.done) })
+	c.state.Store(int32(StateClosed))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.channel != nil && !c.channel.IsClosed() {
+		if err := c.channel.Close(); err != nil {
+			return fmt.Errorf("failed to close amqp channel: %w", err)
+		}
+	}
+	if c.conn != nil && !c.conn.IsClosed() {
+		if err := c.conn.Close(); err != nil {
+			return fmt.Errorf("failed to close amqp connection: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package rabbit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// newTestConnection возвращает соединение, у которого первые failures переподключений
+// заканчиваются ошибкой. Задержки не ждем, а записываем в waits.
+func newTestConnection(minDelay, maxDelay time.Duration, failures int) (*connection, *[]time.Duration, *int) {
+	c := newConnection("amqp://test", nil, minDelay, maxDelay, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	c.state.Store(int32(StateReconnecting))
+
+	var waits []time.Duration
+	c.after = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		ch := make(chan time.Time, 1)
+		ch <- time.Now()
+		return ch
+	}
+
+	dials := 0
+	c.redial = func() error {
+		dials++
+		if dials <= failures {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	return c, &waits, &dials
+}
+
+func TestReconnectBacksOffExponentially(t *testing.T) {
+	const minDelay, maxDelay = 100 * time.Millisecond, time.Second
+	c, waits, dials := newTestConnection(minDelay, maxDelay, 6)
+
+	c.reconnect()
+
+	if *dials != 7 || c.State() != StateConnected {
+		t.Fatalf("dials = %d, state = %s, want 7 and connected", *dials, c.State())
+	}
+	if err := c.Ready(context.Background()); err != nil {
+		t.Fatalf("Ready after reconnect: %v", err)
+	}
+
+	// Задержка удваивается до maxDelay, разброс - от половины задержки до полной
+	want := []time.Duration{100, 200, 400, 800, 1000, 1000, 1000}
+	if len(*waits) != len(want) {
+		t.Fatalf("waits = %v, want %d of them", *waits, len(want))
+	}
+	for i, wait := range *waits {
+		delay := want[i] * time.Millisecond
+		if wait < delay/2 || wait > delay {
+			t.Fatalf("wait %d = %s, want between %s and %s", i+1, wait, delay/2, delay)
+		}
+	}
+}
+
+func TestReconnectStopsOnClose(t *testing.T) {
+	c, _, dials := newTestConnection(time.Millisecond, time.Millisecond, 1<<30)
+
+	// Первая попытка проходит, а перед второй соединение закрывают
+	c.after = func(time.Duration) <-chan time.Time {
+		if *dials > 0 {
+			c.Close()
+			return nil
+		}
+		ch := make(chan time.Time, 1)
+		ch <- time.Now()
+		return ch
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.reconnect()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("reconnect did not stop after Close")
+	}
+	if *dials != 1 || c.State() != StateClosed {
+		t.Fatalf("dials = %d, state = %s, want 1 and closed", *dials, c.State())
+	}
+	if err := c.Ready(context.Background()); !errors.Is(err, ErrNotConnected) {
+		t.Fatalf("Ready = %v, want ErrNotConnected", err)
+	}
+}
+
+func TestNewConnectionDelayDefaults(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	c := newConnection("amqp://test", nil, 0, 0, log)
+	if c.minDelay != defaultReconnectDelay || c.maxDelay != defaultReconnectMaxDelay {
+		t.Fatalf("delays = %s..%s, want defaults", c.minDelay, c.maxDelay)
+	}
+
+	c = newConnection("amqp://test", nil, time.Minute, time.Second, log)
+	if c.minDelay != time.Minute || c.maxDelay != time.Minute {
+		t.Fatalf("delays = %s..%s, want the max raised to the min", c.minDelay, c.maxDelay)
+	}
+}
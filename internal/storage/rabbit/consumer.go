@@ -0,0 +1,342 @@
+package rabbit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kerilOvs/profile_sevice/internal/config"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/kerilOvs/profile_sevice/internal/events"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	consumerTag = "profile-service"
+
+	defaultPrefetch    = 10
+	defaultRetryDelay  = 5 * time.Second
+	defaultMaxAttempts = 10
+	defaultStopTimeout = 10 * time.Second
+
+	// retryCountHeader - сколько раз сообщение уже откладывалось в retry очередь
+	retryCountHeader = "x-retry-count"
+)
+
+// errPoison - сообщение, которое не обработать повтором: битый конверт или паника обработчика.
+var errPoison = errors.New("poison message")
+
+// Handler обрабатывает входящее событие. Ошибки вида errorsExt.ErrValidation и
+// errorsExt.ErrUnprocessable отправляют сообщение в dead-letter очередь,
+// остальные считаются временными: сообщение возвращается в очередь через RetryDelay,
+// а после MaxAttempts попыток тоже уходит в dead-letter очередь.
+type Handler interface {
+	Handle(ctx context.Context, event events.Envelope) error
+}
+
+// Consumer читает события из своей очереди с ручным подтверждением. Очередь привязана
+// к exchange по routing keys и отдает неисправимые сообщения в dead-letter очередь.
+//
+// Сообщение с временной ошибкой не ждет в цикле чтения: его копия публикуется в retry
+// очередь с TTL = RetryDelay, откуда по истечении TTL возвращается в основную очередь.
+type Consumer struct {
+	conn        *connection
+	handler     Handler
+	log         *slog.Logger
+	exchange    string
+	queue       string
+	dlq         string
+	retryQueue  string
+	routingKeys []string
+	prefetch    int
+	retryDelay  time.Duration
+	maxAttempts int
+	stopTimeout time.Duration
+
+	// publish - отправка с подтверждением брокера, в тестах подменяется
+	publish func(ctx context.Context, exchange, key string, msg amqp.Publishing) error
+
+	ctx context.Context
+
+	mu       sync.Mutex
+	channel  *amqp.Channel
+	stopping bool
+	loops    sync.WaitGroup
+}
+
+func NewConsumer(cfg *config.RabbitConfig, handler Handler, routingKeys []string, log *slog.Logger) *Consumer {
+	log = log.WithGroup("rabbit_consumer")
+	c := &Consumer{
+		handler:     handler,
+		log:         log,
+		exchange:    cfg.Consumer.Exchange,
+		queue:       cfg.Consumer.Queue,
+		dlq:         cfg.Consumer.DeadLetterQueue,
+		retryQueue:  cfg.Consumer.RetryQueue,
+		routingKeys: routingKeys,
+		prefetch:    cfg.Consumer.Prefetch,
+		retryDelay:  cfg.Consumer.RetryDelay,
+		maxAttempts: cfg.Consumer.MaxAttempts,
+		stopTimeout: cfg.Consumer.StopTimeout,
+	}
+	if c.dlq == "" {
+		c.dlq = c.queue + ".dlq"
+	}
+	if c.retryQueue == "" {
+		c.retryQueue = c.queue + ".retry"
+	}
+	if c.prefetch <= 0 {
+		c.prefetch = defaultPrefetch
+	}
+	if c.retryDelay <= 0 {
+		c.retryDelay = defaultRetryDelay
+	}
+	if c.maxAttempts <= 0 {
+		c.maxAttempts = defaultMaxAttempts
+	}
+	if c.stopTimeout <= 0 {
+		c.stopTimeout = defaultStopTimeout
+	}
+
+	c.conn = newConnection(cfg.Url, c.subscribe, cfg.ReconnectDelay, cfg.ReconnectMaxDelay, log)
+	c.publish = c.conn.publish
+	return c
+}
+
+// Start подключается и начинает чтение. После переподключения чтение возобновляется само.
+// ctx передается обработчику и не должен отменяться раньше Stop.
+func (c *Consumer) Start(ctx context.Context) error {
+	c.ctx = ctx
+	return c.conn.connect()
+}
+
+// subscribe объявляет очереди и запускает цикл чтения на новом канале.
+func (c *Consumer) subscribe(channel *amqp.Channel) error {
+	err := channel.ExchangeDeclare(c.exchange, amqp.ExchangeTopic, true, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to declare exchange %s: %w", c.exchange, err)
+	}
+
+	if _, err := channel.QueueDeclare(c.dlq, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare dead-letter queue: %w", err)
+	}
+
+	// Отклоненное сообщение уходит через default exchange прямо в dlq
+	args := amqp.Table{
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": c.dlq,
+	}
+	if _, err := channel.QueueDeclare(c.queue, true, false, false, false, args); err != nil {
+		return fmt.Errorf("failed to declare consumer queue: %w", err)
+	}
+
+	// В retry очереди никто не читает: TTL задается каждому сообщению (Expiration),
+	// истекшие сообщения возвращаются через default exchange в основную очередь
+	retryArgs := amqp.Table{
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": c.queue,
+	}
+	if _, err := channel.QueueDeclare(c.retryQueue, true, false, false, false, retryArgs); err != nil {
+		return fmt.Errorf("failed to declare retry queue: %w", err)
+	}
+
+	for _, key := range c.routingKeys {
+		if err := channel.QueueBind(c.queue, key, c.exchange, false, nil); err != nil {
+			return fmt.Errorf("failed to bind consumer queue to %s: %w", key, err)
+		}
+	}
+
+	if err := channel.Qos(c.prefetch, 0, false); err != nil {
+		return fmt.Errorf("failed to set prefetch: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopping {
+		return errors.New("consumer is stopping")
+	}
+
+	deliveries, err := channel.Consume(c.queue, consumerTag, false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to consume %s: %w", c.queue, err)
+	}
+
+	c.channel = channel
+	c.loops.Add(1)
+	go c.loop(deliveries)
+	return nil
+}
+
+// loop завершается, когда канал закрыт или подписка отменена в Stop.
+func (c *Consumer) loop(deliveries <-chan amqp.Delivery) {
+	defer c.loops.Done()
+	for d := range deliveries {
+		c.process(d)
+	}
+}
+
+func (c *Consumer) process(d amqp.Delivery) {
+	var event events.Envelope
+	err := json.Unmarshal(d.Body, &event)
+	if err == nil && event.Type == "" {
+		err = errors.New("envelope without type")
+	}
+	if err != nil {
+		c.reject(d, fmt.Errorf("%w: %w", errPoison, err))
+		return
+	}
+
+	ctx := events.WithCorrelationID(c.ctx, event.CorrelationID)
+	log := c.log.With(slog.String("type", event.Type), slog.String("id", event.ID))
+
+	err = c.handle(ctx, event)
+	switch {
+	case err == nil:
+		if err := d.Ack(false); err != nil {
+			log.Warn("failed to ack message", slog.Any("error", err))
+		}
+	case permanent(err):
+		c.reject(d, err)
+	default:
+		c.retry(d, err, log)
+	}
+}
+
+func (c *Consumer) handle(ctx context.Context, event events.Envelope) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: handler panic: %v", errPoison, r)
+		}
+	}()
+	return c.handler.Handle(ctx, event)
+}
+
+func permanent(err error) bool {
+	return errors.Is(err, errPoison) ||
+		errors.Is(err, errorsExt.ErrValidation) ||
+		errors.Is(err, errorsExt.ErrUnprocessable)
+}
+
+// reject отправляет сообщение в dead-letter очередь.
+func (c *Consumer) reject(d amqp.Delivery, reason error) {
+	c.log.Error("moving message to dead-letter queue",
+		slog.String("message_id", d.MessageId),
+		slog.String("routing_key", d.RoutingKey),
+		slog.Any("reason", reason))
+
+	if err := d.Nack(false, false); err != nil {
+		c.log.Warn("failed to reject message", slog.Any("error", err))
+	}
+}
+
+// retry откладывает сообщение в retry очередь и подтверждает оригинал. Исчерпавшее
+// попытки сообщение уходит в dead-letter очередь.
+func (c *Consumer) retry(d amqp.Delivery, reason error, log *slog.Logger) {
+	attempt := retryCount(d.Headers) + 1
+	if attempt >= c.maxAttempts {
+		c.reject(d, fmt.Errorf("gave up after %d attempts: %w", attempt, reason))
+		return
+	}
+
+	log.Warn("failed to handle event, will retry",
+		slog.Any("error", reason),
+		slog.Int("attempt", attempt),
+		slog.Duration("retry_delay", c.retryDelay))
+
+	headers := amqp.Table{}
+	for key, value := range d.Headers {
+		headers[key] = value
+	}
+	headers[retryCountHeader] = int32(attempt)
+
+	ctx, cancel := context.WithTimeout(c.ctx, msgTimeout)
+	defer cancel()
+
+	err := c.publish(ctx, "", c.retryQueue, amqp.Publishing{
+		Headers:         headers,
+		ContentType:     d.ContentType,
+		ContentEncoding: d.ContentEncoding,
+		DeliveryMode:    amqp.Persistent,
+		CorrelationId:   d.CorrelationId,
+		MessageId:       d.MessageId,
+		Timestamp:       d.Timestamp,
+		Type:            d.Type,
+		AppId:           d.AppId,
+		Expiration:      strconv.FormatInt(c.retryDelay.Milliseconds(), 10),
+		Body:            d.Body,
+	})
+	if err != nil {
+		// Сообщение не должно потеряться: возвращаем его в основную очередь сразу
+		log.Warn("failed to schedule retry, requeueing", slog.Any("error", err))
+		if err := d.Nack(false, true); err != nil {
+			log.Warn("failed to requeue message", slog.Any("error", err))
+		}
+		return
+	}
+
+	if err := d.Ack(false); err != nil {
+		// Копия уже в retry очереди, оригинал брокер доставит повторно: обработчик идемпотентен
+		log.Warn("failed to ack retried message", slog.Any("error", err))
+	}
+}
+
+// retryCount читает счетчик повторов. Заголовок может прийти любым целым типом AMQP.
+func retryCount(headers amqp.Table) int {
+	switch n := headers[retryCountHeader].(type) {
+	case int8:
+		return int(n)
+	case int16:
+		return int(n)
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	case int:
+		return n
+	}
+	return 0
+}
+
+// Ready - проверка готовности для health check.
+func (c *Consumer) Ready(ctx context.Context) error {
+	return c.conn.Ready(ctx)
+}
+
+// Stop отменяет подписку, дожидается обработки текущего сообщения (не дольше StopTimeout)
+// и закрывает соединение. Неподтвержденные сообщения брокер вернет в очередь.
+func (c *Consumer) Stop() error {
+	c.mu.Lock()
+	if c.stopping {
+		c.mu.Unlock()
+		return nil
+	}
+	c.stopping = true
+	channel := c.channel
+	c.mu.Unlock()
+
+	if channel != nil && !channel.IsClosed() {
+		if err := channel.Cancel(consumerTag, false); err != nil {
+			c.log.Warn("failed to cancel consumer", slog.Any("error", err))
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.loops.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(c.stopTimeout):
+		c.log.Warn("consumer stop timed out, closing connection", slog.Duration("timeout", c.stopTimeout))
+	}
+
+	return c.conn.Close()
+}
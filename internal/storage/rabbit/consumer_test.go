@@ -0,0 +1,188 @@
+package rabbit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/kerilOvs/profile_sevice/internal/config"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/kerilOvs/profile_sevice/internal/events"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// acknowledger запоминает, чем закончилась обработка доставки.
+type acknowledger struct {
+	acked   bool
+	nacked  bool
+	requeue bool
+}
+
+func (a *acknowledger) Ack(uint64, bool) error { a.acked = true; return nil }
+
+func (a *acknowledger) Nack(_ uint64, _ bool, requeue bool) error {
+	a.nacked, a.requeue = true, requeue
+	return nil
+}
+
+func (a *acknowledger) Reject(_ uint64, requeue bool) error {
+	a.nacked, a.requeue = true, requeue
+	return nil
+}
+
+type handlerFunc func(ctx context.Context, event events.Envelope) error
+
+func (f handlerFunc) Handle(ctx context.Context, event events.Envelope) error { return f(ctx, event) }
+
+type published struct {
+	exchange, key string
+	msg           amqp.Publishing
+}
+
+func newTestConsumer(t *testing.T, handler Handler, publishErr error) (*Consumer, *[]published) {
+	t.Helper()
+
+	cfg := &config.RabbitConfig{Consumer: config.ConsumerConfig{
+		Queue:       "profile.auth",
+		RetryDelay:  1500 * time.Millisecond,
+		MaxAttempts: 3,
+	}}
+	c := NewConsumer(cfg, handler, []string{"auth.account.#"}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	c.ctx = context.Background()
+
+	var sent []published
+	c.publish = func(_ context.Context, exchange, key string, msg amqp.Publishing) error {
+		if publishErr != nil {
+			return publishErr
+		}
+		sent = append(sent, published{exchange, key, msg})
+		return nil
+	}
+	return c, &sent
+}
+
+func delivery(t *testing.T, headers amqp.Table) (amqp.Delivery, *acknowledger) {
+	t.Helper()
+
+	event, err := events.New(context.Background(), "auth.account.deleted", map[string]string{"user_id": "42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ack := &acknowledger{}
+	return amqp.Delivery{
+		Acknowledger: ack,
+		Headers:      headers,
+		ContentType:  events.ContentType,
+		MessageId:    event.ID,
+		Type:         event.Type,
+		Body:         body,
+	}, ack
+}
+
+var errTemporary = errors.New("database is down")
+
+func TestConsumerAcksHandledMessage(t *testing.T) {
+	c, sent := newTestConsumer(t, handlerFunc(func(context.Context, events.Envelope) error { return nil }), nil)
+	d, ack := delivery(t, nil)
+
+	c.process(d)
+	if !ack.acked || ack.nacked || len(*sent) != 0 {
+		t.Fatalf("ack = %+v, retries = %d", ack, len(*sent))
+	}
+}
+
+func TestConsumerRejectsPermanentErrors(t *testing.T) {
+	for name, err := range map[string]error{
+		"validation": errorsExt.Validation(errorsExt.CodeInvalidEvent, "bad event"),
+		"panic":      nil,
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, sent := newTestConsumer(t, handlerFunc(func(context.Context, events.Envelope) error {
+				if err == nil {
+					panic("boom")
+				}
+				return err
+			}), nil)
+			d, ack := delivery(t, nil)
+
+			c.process(d)
+			if !ack.nacked || ack.requeue || len(*sent) != 0 {
+				t.Fatalf("ack = %+v, retries = %d, want dead-lettered", ack, len(*sent))
+			}
+		})
+	}
+
+	c, _ := newTestConsumer(t, handlerFunc(func(context.Context, events.Envelope) error { return nil }), nil)
+	ack := &acknowledger{}
+	c.process(amqp.Delivery{Acknowledger: ack, Body: []byte("not json")})
+	if !ack.nacked || ack.requeue {
+		t.Fatalf("malformed message: ack = %+v, want dead-lettered", ack)
+	}
+}
+
+func TestConsumerRetriesThroughRetryQueue(t *testing.T) {
+	c, sent := newTestConsumer(t, handlerFunc(func(context.Context, events.Envelope) error { return errTemporary }), nil)
+
+	d, ack := delivery(t, amqp.Table{"x-custom": "kept"})
+	c.process(d)
+
+	if !ack.acked || ack.nacked {
+		t.Fatalf("ack = %+v, want the original acked", ack)
+	}
+	if len(*sent) != 1 {
+		t.Fatalf("retries = %d, want 1", len(*sent))
+	}
+	retry := (*sent)[0]
+	if retry.exchange != "" || retry.key != "profile.auth.retry" {
+		t.Fatalf("retry published to %q/%q", retry.exchange, retry.key)
+	}
+	msg := retry.msg
+	if msg.Expiration != "1500" || msg.Headers[retryCountHeader] != int32(1) || msg.Headers["x-custom"] != "kept" ||
+		msg.MessageId != d.MessageId || msg.DeliveryMode != amqp.Persistent || string(msg.Body) != string(d.Body) {
+		t.Fatalf("retry message = %+v", msg)
+	}
+
+	// Вторая неудача увеличивает счетчик, третья (MaxAttempts) отправляет в dead-letter очередь
+	d, ack = delivery(t, msg.Headers)
+	c.process(d)
+	if !ack.acked || len(*sent) != 2 || (*sent)[1].msg.Headers[retryCountHeader] != int32(2) {
+		t.Fatalf("second attempt: ack = %+v, retries = %+v", ack, *sent)
+	}
+
+	d, ack = delivery(t, (*sent)[1].msg.Headers)
+	c.process(d)
+	if !ack.nacked || ack.requeue || len(*sent) != 2 {
+		t.Fatalf("last attempt: ack = %+v, retries = %d, want dead-lettered", ack, len(*sent))
+	}
+}
+
+func TestConsumerRequeuesWhenRetryQueueUnavailable(t *testing.T) {
+	c, _ := newTestConsumer(t, handlerFunc(func(context.Context, events.Envelope) error { return errTemporary }), ErrNotConnected)
+	d, ack := delivery(t, nil)
+
+	c.process(d)
+	if ack.acked || !ack.nacked || !ack.requeue {
+		t.Fatalf("ack = %+v, want requeued", ack)
+	}
+}
+
+func TestRetryCount(t *testing.T) {
+	for _, value := range []any{int8(4), int16(4), int32(4), int64(4), 4} {
+		if got := retryCount(amqp.Table{retryCountHeader: value}); got != 4 {
+			t.Errorf("retryCount(%T) = %d, want 4", value, got)
+		}
+	}
+	if got := retryCount(nil); got != 0 {
+		t.Errorf("retryCount(nil) = %d", got)
+	}
+}
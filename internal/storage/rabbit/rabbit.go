@@ -2,218 +2,187 @@ package rabbit
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-
+	"log/slog"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/kerilOvs/profile_sevice/internal/config"
-	"github.com/kerilOvs/profile_sevice/internal/models"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/kerilOvs/profile_sevice/internal/events"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 const (
-	msgTimeout = 5 * time.Second
+	msgTimeout      = 5 * time.Second
+	defaultExchange = "profile.events"
 )
 
 type Repo struct {
-	conn    *amqp.Connection
-	channel *amqp.Channel
-	queues  map[string]amqp.Queue // Храним информацию об очередях
+	conn     *connection
+	exchange string
+	durable  config.QueueDurability
+	log      *slog.Logger
 
 	tagsQueueName   string
 	photosQueueName string
 	anketsQueueName string
-}
 
-func New(ctx context.Context, cfg *config.RabbitConfig) (*Repo, error) {
-	tagsQueueName := cfg.QueueTagsName
-	photosQueueName := cfg.QueuePhotoName
-	anketsQueueName := cfg.QueueAnketName
+	// Пока получатель не перешел на v2, его очередь привязана к событию старого формата
+	legacyTags  bool
+	legacyAnket bool
+}
 
-	conn, err := amqp.Dial(cfg.Url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Rabbit: %w", err)
+// New подключается к RabbitMQ и объявляет topic exchange и наши очереди. При потере
+// соединения Repo переподключается сам, публикации в это время возвращают ErrNotConnected.
+// Очереди тегов и анкет получают старый формат, пока он включен в eventsCfg, затем - v2.
+func New(ctx context.Context, cfg *config.RabbitConfig, eventsCfg config.EventsConfig, log *slog.Logger) (*Repo, error) {
+	log = log.WithGroup("rabbit")
+	repo := &Repo{
+		exchange:        cfg.Exchange,
+		durable:         cfg.Durable,
+		log:             log,
+		tagsQueueName:   cfg.QueueTagsName,
+		legacyTags:      eventsCfg.LegacyTags,
+		legacyAnket:     eventsCfg.LegacyAnket,
+		photosQueueName: cfg.QueuePhotoName,
+		anketsQueueName: cfg.QueueAnketName,
 	}
-
-	channel, err := conn.Channel()
-	if err != nil {
-		return nil, fmt.Errorf("failed to open a channel: %w", err)
+	if repo.exchange == "" {
+		repo.exchange = defaultExchange
 	}
 
-	// Объявляем три разные очереди
-	queues := make(map[string]amqp.Queue)
-
-	tagsQueue, err := channel.QueueDeclare(
-		tagsQueueName,
-		false, // durable
-		false, // delete when unused
-		false, // exclusive
-		false, // no-wait
-		nil,   // arguments
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to declare tags queue: %w", err)
+	repo.conn = newConnection(cfg.Url, repo.declareTopology, cfg.ReconnectDelay, cfg.ReconnectMaxDelay, log)
+	if err := repo.conn.connect(); err != nil {
+		return nil, err
 	}
-	queues[tagsQueueName] = tagsQueue
 
-	photosQueue, err := channel.QueueDeclare(
-		photosQueueName,
-		false, // durable
-		false, // delete when unused
-		false, // exclusive
-		false, // no-wait
-		nil,   // arguments
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to declare photos queue: %w", err)
-	}
-	queues[photosQueueName] = photosQueue
+	return repo, nil
+}
 
-	anketsQueue, err := channel.QueueDeclare(
-		anketsQueueName,
-		false, // durable
-		false, // delete when unused
-		false, // exclusive
+// declareTopology вызывается при каждом (пере)подключении. Очереди из конфига - подписки
+// сервиса подбора, они привязываются к exchange по типу события. Остальные команды
+// объявляют и привязывают свои очереди сами.
+func (r *Repo) declareTopology(channel *amqp.Channel) error {
+	err := channel.ExchangeDeclare(
+		r.exchange,
+		amqp.ExchangeTopic,
+		true,  // durable
+		false, // auto-deleted
+		false, // internal
 		false, // no-wait
 		nil,   // arguments
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to declare ankets queue: %w", err)
+		return fmt.Errorf("failed to declare exchange %s: %w", r.exchange, err)
+	}
+
+	tagsKey, staleTagsKey := formatKeys(r.legacyTags, events.TypeTagsUpdated, events.TypeTagsUpdatedV2)
+	anketKey, staleAnketKey := formatKeys(r.legacyAnket, events.TypeAnketUpdated, events.TypeAnketUpdatedV2)
+
+	queues := []struct {
+		kind, name string
+		durable    bool
+		routingKey string
+		staleKey   string // привязка прежнего формата, снимается при переключении
+	}{
+		{"tags", r.tagsQueueName, r.durable.Tags, tagsKey, staleTagsKey},
+		{"photos", r.photosQueueName, r.durable.Photo, events.TypePhotoUpdated, ""},
+		{"ankets", r.anketsQueueName, r.durable.Anket, anketKey, staleAnketKey},
+	}
+
+	for _, q := range queues {
+		if q.name == "" {
+			continue
+		}
+
+		_, err := channel.QueueDeclare(
+			q.name,
+			q.durable, // durable
+			false,     // delete when unused
+			false,     // exclusive
+			false,     // no-wait
+			nil,       // arguments
+		)
+		if err != nil {
+			return fmt.Errorf("failed to declare %s queue: %w", q.kind, err)
+		}
+
+		if err := channel.QueueBind(q.name, q.routingKey, r.exchange, false, nil); err != nil {
+			return fmt.Errorf("failed to bind %s queue: %w", q.kind, err)
+		}
+
+		if q.staleKey != "" {
+			if err := channel.QueueUnbind(q.name, q.staleKey, r.exchange, nil); err != nil {
+				return fmt.Errorf("failed to unbind %s queue from %s: %w", q.kind, q.staleKey, err)
+			}
+		}
 	}
-	queues[anketsQueueName] = anketsQueue
 
-	repo := &Repo{
-		conn:            conn,
-		channel:         channel,
-		queues:          queues,
-		tagsQueueName:   tagsQueueName,
-		photosQueueName: photosQueueName,
-		anketsQueueName: anketsQueueName,
-	}
-	return repo, nil
+	return nil
 }
 
-func (r *Repo) Close() error {
-	if err := r.channel.Close(); err != nil {
-		return fmt.Errorf("failed to close amqp channel: %w", err)
-	}
-
-	if err := r.conn.Close(); err != nil {
-		return fmt.Errorf("failed to close amqp connection: %w", err)
+// formatKeys возвращает routing key текущего формата и ключ другого формата, от которого
+// очередь нужно отвязать.
+func formatKeys(legacy bool, legacyKey, v2Key string) (key, stale string) {
+	if legacy {
+		return legacyKey, v2Key
 	}
-
-	return nil
+	return v2Key, legacyKey
 }
 
-type Tags struct {
-	UserID uuid.UUID `json:"user_id"`
-	Tags   string `json:"tags"`
+func (r *Repo) Close() error {
+	return r.conn.Close()
 }
 
-func (r *Repo) PublishTags(ctx context.Context, tags Tags) error {
-	tagsDto := Tags{
-		UserID: tags.UserID,
-		Tags:   tags.Tags,
-	}
-
-	body, err := json.Marshal(tagsDto)
-	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
-	}
-
-	ctx, cancel := context.WithTimeout(ctx, msgTimeout)
-	defer cancel()
-
-	err = r.channel.PublishWithContext(
-		ctx,
-		"",              // exchange
-		r.tagsQueueName, // routing key (имя очереди)
-		false,           // mandatory
-		false,           // immediate
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		})
-	if err != nil {
-		return fmt.Errorf("failed to publish tags: %w", err)
-	}
-
-	return nil
+// State возвращает состояние соединения с брокером.
+func (r *Repo) State() State {
+	return r.conn.State()
 }
 
-type Photo struct {
-	ID   uuid.UUID `json:"user_id"`
-	Path string    `json:"image_url"`
+// Ready - проверка готовности для health check.
+func (r *Repo) Ready(ctx context.Context) error {
+	return r.conn.Ready(ctx)
 }
 
-func (r *Repo) PublishPhoto(ctx context.Context, photo Photo) error {
-	photoDto := Photo{
-		ID:   photo.ID,
-		Path: photo.Path,
-	}
-
-	body, err := json.Marshal(photoDto)
+// Message - сообщение, которое Publish отправляет для события с routing key event.Type.
+// Тело собирает events.Body, поэтому другие брокеры (NATS) отдают получателям то же самое.
+func Message(event events.Envelope) (amqp.Publishing, error) {
+	body, contentType, err := events.Body(event)
 	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
-	}
-
-	ctx, cancel := context.WithTimeout(ctx, msgTimeout)
-	defer cancel()
-
-	err = r.channel.PublishWithContext(
-		ctx,
-		"",                // exchange
-		r.photosQueueName, // routing key (имя очереди)
-		false,             // mandatory
-		false,             // immediate
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		})
-	if err != nil {
-		return fmt.Errorf("failed to publish photo: %w", err)
-	}
-
-	return nil
-}
-
-type UserAnket struct {
-	ID        uuid.UUID         `json:"user_id"`
-	Gender    models.UserGender `json:"gender"`
-	BirthDate string            `json:"birth_date"`
-	//Description string    `json:"description"`
+		return amqp.Publishing{}, err
+	}
+
+	return amqp.Publishing{
+		ContentType:   contentType,
+		DeliveryMode:  amqp.Persistent,
+		MessageId:     event.ID,
+		CorrelationId: event.CorrelationID,
+		Type:          event.Type,
+		Timestamp:     event.Time,
+		Body:          body,
+	}, nil
 }
 
-func (r *Repo) PublishAnket(ctx context.Context, anket UserAnket) error {
-	anketDto := UserAnket{
-		ID:        anket.ID,
-		Gender:    anket.Gender,
-		BirthDate: anket.BirthDate,
-	}
-
-	body, err := json.Marshal(anketDto)
+// Publish отправляет событие в exchange с routing key, равным типу события.
+// Событие, на которое никто не подписан, не ошибка: оно пишется в лог и не задерживает outbox.
+func (r *Repo) Publish(ctx context.Context, event events.Envelope) error {
+	msg, err := Message(event)
 	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+		return err
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, msgTimeout)
 	defer cancel()
 
-	err = r.channel.PublishWithContext(
-		ctx,
-		"",                // exchange
-		r.anketsQueueName, // routing key (имя очереди)
-		false,             // mandatory
-		false,             // immediate
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		})
+	err = r.conn.publish(ctx, r.exchange, event.Type, msg)
+	if errors.Is(err, ErrUnroutable) {
+		r.log.Warn("event has no subscribers", slog.String("type", event.Type), slog.String("id", event.ID))
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to publish anket: %w", err)
+		return errorsExt.Upstream(errorsExt.CodeBrokerUnavailable, "failed to publish "+event.Type, err)
 	}
 
 	return nil
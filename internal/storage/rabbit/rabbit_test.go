@@ -0,0 +1,84 @@
+package rabbit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/events"
+	"github.com/kerilOvs/profile_sevice/internal/models"
+)
+
+// Очереди сервиса подбора читают эти типы в формате исходного сервиса: голый DTO
+// с content-type application/json. Тело должно совпадать байт в байт.
+func TestMessageKeepsLegacyWireFormat(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.MustParse("7d7cf2a4-6c3b-4f43-9a43-3a1e5c6b2f10")
+
+	tests := []struct {
+		eventType string
+		data      any
+		want      string
+	}{
+		{
+			events.TypeTagsUpdated,
+			events.TagsUpdated{UserID: userID, Tags: "music travel"},
+			`{"user_id":"7d7cf2a4-6c3b-4f43-9a43-3a1e5c6b2f10","tags":"music travel"}`,
+		},
+		{
+			events.TypePhotoUpdated,
+			events.PhotoUpdated{UserID: userID, ImageURL: "http://localhost:9000/pub/mybucket/1.jpg"},
+			`{"user_id":"7d7cf2a4-6c3b-4f43-9a43-3a1e5c6b2f10","image_url":"http://localhost:9000/pub/mybucket/1.jpg"}`,
+		},
+		{
+			events.TypeAnketUpdated,
+			events.AnketUpdated{UserID: userID, Gender: models.GenderMale, BirthDate: "17/05/1990"},
+			`{"user_id":"7d7cf2a4-6c3b-4f43-9a43-3a1e5c6b2f10","gender":"MALE","birth_date":"17/05/1990"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.eventType, func(t *testing.T) {
+			event, err := events.New(ctx, tt.eventType, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			msg, err := Message(event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(msg.Body) != tt.want {
+				t.Fatalf("body = %s\nwant   %s", msg.Body, tt.want)
+			}
+			if msg.ContentType != "application/json" || msg.Type != tt.eventType || msg.MessageId != event.ID {
+				t.Fatalf("properties: content type %q, type %q, id %q", msg.ContentType, msg.Type, msg.MessageId)
+			}
+		})
+	}
+}
+
+func TestMessageWrapsNewTypesInEnvelope(t *testing.T) {
+	data := events.TagsUpdatedV2{UserID: uuid.New(), Version: 3, Tags: []events.Tag{{ID: uuid.New(), Value: "chess"}}}
+	event, err := events.New(context.Background(), events.TypeTagsUpdatedV2, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := Message(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.ContentType != events.ContentType {
+		t.Fatalf("content type = %q, want %q", msg.ContentType, events.ContentType)
+	}
+
+	var got events.Envelope
+	if err := json.Unmarshal(msg.Body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != event.ID || got.Type != events.TypeTagsUpdatedV2 || got.SchemaVersion != 2 ||
+		!got.Time.Equal(event.Time) || string(got.Data) != string(event.Data) {
+		t.Fatalf("envelope = %+v, want %+v", got, event)
+	}
+}
@@ -0,0 +1,671 @@
+// Package storagetest - общий набор проверок, который должна проходить любая
+// реализация storage.UserStorage.
+package storagetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	errorsExt "github.com/kerilOvs/profile_sevice/internal/errorsExt"
+	"github.com/kerilOvs/profile_sevice/internal/models"
+	"github.com/kerilOvs/profile_sevice/internal/storage"
+)
+
+// Factory возвращает пустое хранилище для одного теста.
+type Factory func(t *testing.T) storage.UserStorage
+
+// Run запускает проверки подтестами. Тесты не параллельные: factory может
+// очищать общую базу.
+func Run(t *testing.T, factory Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s storage.UserStorage)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"GetUsersByIDs", testGetUsersByIDs},
+		{"UpdateUser", testUpdateUser},
+		{"DeleteUserCascades", testDeleteUserCascades},
+		{"Photos", testPhotos},
+		{"SetPrimaryPhoto", testSetPrimaryPhoto},
+		{"Tags", testTags},
+		{"BumpTagsVersion", testBumpTagsVersion},
+		{"ListUsers", testListUsers},
+		{"ListUsersAfter", testListUsersAfter},
+		{"SearchUsers", testSearchUsers},
+		{"SearchUsersFullText", testSearchUsersFullText},
+		{"AuditRecords", testAuditRecords},
+		{"TxCommit", testTxCommit},
+		{"TxRollback", testTxRollback},
+		{"TxCanceledContext", testTxCanceledContext},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, factory(t))
+		})
+	}
+}
+
+func testCreateAndGet(t *testing.T, s storage.UserStorage) {
+	ctx := context.Background()
+	about := "about"
+	user := newUser("Ivan")
+	user.AboutMyself = &about
+	mustCreate(t, s, user)
+
+	got := mustGet(t, s, user.ID)
+	if got.Name != "Ivan" || got.Surname != user.Surname || got.AboutMyself == nil || *got.AboutMyself != about {
+		t.Fatalf("GetUserByID = %+v, want %+v", got, user)
+	}
+	if *got.Gender != models.GenderMale {
+		t.Fatalf("gender = %v, want %v", *got.Gender, models.GenderMale)
+	}
+
+	missing, err := s.GetUserByID(ctx, uuid.New())
+	if err != nil || missing != nil {
+		t.Fatalf("GetUserByID(missing) = %v, %v; want nil, nil", missing, err)
+	}
+
+	err = s.CreateUser(ctx, newUserWithID(user.ID, "Other"))
+	expectErr(t, "CreateUser(duplicate)", err, errorsExt.ErrConflict)
+}
+
+func testGetUsersByIDs(t *testing.T, s storage.UserStorage) {
+	ctx := context.Background()
+	a, b, c := newUser("A"), newUser("B"), newUser("C")
+	for _, user := range []*models.User{a, b, c} {
+		mustCreate(t, s, user)
+	}
+	tag := mustAddTag(t, s, b.ID, "music")
+
+	users, err := s.GetUsersByIDs(ctx, []uuid.UUID{b.ID, uuid.New(), a.ID})
+	if err != nil {
+		t.Fatalf("GetUsersByIDs: %v", err)
+	}
+	if got := userIDs(users); !sameIDs(got, []uuid.UUID{a.ID, b.ID}) {
+		t.Fatalf("GetUsersByIDs = %v, want %v and %v", got, a.ID, b.ID)
+	}
+	for _, user := range users {
+		if user.ID == b.ID && (len(user.Tags) != 1 || user.Tags[0].ID != tag.ID) {
+			t.Fatalf("tags not loaded: %+v", user.Tags)
+		}
+	}
+
+	users, err = s.GetUsersByIDs(ctx, nil)
+	if err != nil || len(users) != 0 {
+		t.Fatalf("GetUsersByIDs(nil) = %v, %v", users, err)
+	}
+}
+
+func testUpdateUser(t *testing.T, s storage.UserStorage) {
+	ctx := context.Background()
+	user := newUser("Ivan")
+	mustCreate(t, s, user)
+
+	bannedAt := time.Now()
+	err := s.UpdateUser(ctx, user.ID, map[string]interface{}{
+		"surname":    "Petrov",
+		"hidden":     true,
+		"banned_at":  bannedAt,
+		"ban_reason": "spam",
+	})
+	if err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	got := mustGet(t, s, user.ID)
+	if got.Surname != "Petrov" || !got.Hidden || got.BannedAt == nil || got.BanReason == nil || *got.BanReason != "spam" {
+		t.Fatalf("after update = %+v", got)
+	}
+
+	if err := s.UpdateUser(ctx, user.ID, map[string]interface{}{"banned_at": nil, "ban_reason": nil}); err != nil {
+		t.Fatalf("UpdateUser(nil): %v", err)
+	}
+	if err := s.UpdateUserName(ctx, user.ID, "Petr"); err != nil {
+		t.Fatalf("UpdateUserName: %v", err)
+	}
+	if err := s.UpdateUserAbout(ctx, user.ID, "hello"); err != nil {
+		t.Fatalf("UpdateUserAbout: %v", err)
+	}
+	got = mustGet(t, s, user.ID)
+	if got.BannedAt != nil || got.BanReason != nil {
+		t.Fatalf("ban not cleared: %+v", got)
+	}
+	if got.Name != "Petr" || got.AboutMyself == nil || *got.AboutMyself != "hello" {
+		t.Fatalf("after special updates = %+v", got)
+	}
+
+	missing := uuid.New()
+	expectErr(t, "UpdateUser(missing)", s.UpdateUser(ctx, missing, map[string]interface{}{"name": "x"}), errorsExt.ErrNotFound)
+	expectErr(t, "UpdateUserName(missing)", s.UpdateUserName(ctx, missing, "x"), errorsExt.ErrNotFound)
+	expectErr(t, "UpdateUserSurname(missing)", s.UpdateUserSurname(ctx, missing, "x"), errorsExt.ErrNotFound)
+	expectErr(t, "UpdateUserAbout(missing)", s.UpdateUserAbout(ctx, missing, "x"), errorsExt.ErrNotFound)
+}
+
+func testDeleteUserCascades(t *testing.T, s storage.UserStorage) {
+	ctx := context.Background()
+	user, other := newUser("Ivan"), newUser("Anna")
+	mustCreate(t, s, user)
+	mustCreate(t, s, other)
+	mustAddPhoto(t, s, user.ID)
+	mustAddTag(t, s, user.ID, "music")
+	otherPhoto := mustAddPhoto(t, s, other.ID)
+
+	if err := s.DeleteUser(ctx, user.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if got, _ := s.GetUserByID(ctx, user.ID); got != nil {
+		t.Fatalf("user still exists after delete")
+	}
+	if photos, _ := s.GetUserPhotos(ctx, user.ID); len(photos) != 0 {
+		t.Fatalf("photos not deleted with user: %d left", len(photos))
+	}
+	if tags, _ := s.GetUserTags(ctx, user.ID); len(tags) != 0 {
+		t.Fatalf("tags not deleted with user: %d left", len(tags))
+	}
+
+	photos, err := s.GetUserPhotos(ctx, other.ID)
+	if err != nil || len(photos) != 1 || photos[0].ID != otherPhoto.ID {
+		t.Fatalf("other user's photos = %v, %v", photos, err)
+	}
+
+	expectErr(t, "DeleteUser(again)", s.DeleteUser(ctx, user.ID), errorsExt.ErrNotFound)
+}
+
+func testPhotos(t *testing.T, s storage.UserStorage) {
+	ctx := context.Background()
+	user, other := newUser("Ivan"), newUser("Anna")
+	mustCreate(t, s, user)
+	mustCreate(t, s, other)
+
+	first := mustAddPhoto(t, s, user.ID)
+	second := mustAddPhoto(t, s, user.ID)
+
+	photos, err := s.GetUserPhotos(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserPhotos: %v", err)
+	}
+	if ids := photoIDs(photos); !sameIDs(ids, []uuid.UUID{first.ID, second.ID}) {
+		t.Fatalf("GetUserPhotos = %v, want %v and %v", ids, first.ID, second.ID)
+	}
+
+	expectErr(t, "RemovePhoto(other owner)", s.RemovePhoto(ctx, other.ID, first.ID), errorsExt.ErrNotFound)
+	if err := s.RemovePhoto(ctx, user.ID, first.ID); err != nil {
+		t.Fatalf("RemovePhoto: %v", err)
+	}
+	expectErr(t, "RemovePhoto(again)", s.RemovePhoto(ctx, user.ID, first.ID), errorsExt.ErrNotFound)
+
+	photos, _ = s.GetUserPhotos(ctx, user.ID)
+	if ids := photoIDs(photos); !sameIDs(ids, []uuid.UUID{second.ID}) {
+		t.Fatalf("photos after remove = %v, want %v", ids, second.ID)
+	}
+
+	err = s.AddPhoto(ctx, &models.UserPhoto{ID: uuid.New(), UserID: uuid.New(), URL: "x.jpg"})
+	expectErr(t, "AddPhoto(missing user)", err, errorsExt.ErrNotFound)
+}
+
+func testSetPrimaryPhoto(t *testing.T, s storage.UserStorage) {
+	ctx := context.Background()
+	user := newUser("Ivan")
+	mustCreate(t, s, user)
+	photo := mustAddPhoto(t, s, user.ID)
+
+	if err := s.SetPrimaryPhoto(ctx, user.ID, photo.URL); err != nil {
+		t.Fatalf("SetPrimaryPhoto: %v", err)
+	}
+	got := mustGet(t, s, user.ID)
+	if got.PrimaryPhoto == nil || *got.PrimaryPhoto != photo.URL {
+		t.Fatalf("primary photo = %v, want %s", got.PrimaryPhoto, photo.URL)
+	}
+
+	expectErr(t, "SetPrimaryPhoto(missing user)", s.SetPrimaryPhoto(ctx, uuid.New(), photo.URL), errorsExt.ErrNotFound)
+}
+
+func testTags(t *testing.T, s storage.UserStorage) {
+	ctx := context.Background()
+	user, other := newUser("Ivan"), newUser("Anna")
+	mustCreate(t, s, user)
+	mustCreate(t, s, other)
+
+	category := "hobby"
+	tag := &models.UserTag{ID: uuid.New(), UserID: user.ID, Value: "chess", Category: &category}
+	if err := s.AddTag(ctx, tag); err != nil {
+		t.Fatalf("AddTag: %v", err)
+	}
+	plain := mustAddTag(t, s, user.ID, "music")
+
+	tags, err := s.GetUserTags(ctx, user.ID)
+	if err != nil || len(tags) != 2 {
+		t.Fatalf("GetUserTags = %v, %v; want 2 tags", tags, err)
+	}
+	for _, got := range tags {
+		switch got.ID {
+		case tag.ID:
+			if got.Value != "chess" || got.Category == nil || *got.Category != category {
+				t.Fatalf("tag = %+v, want %+v", got, tag)
+			}
+		case plain.ID:
+			if got.Category != nil {
+				t.Fatalf("tag without category = %+v", got)
+			}
+		default:
+			t.Fatalf("unexpected tag %+v", got)
+		}
+	}
+
+	expectErr(t, "RemoveTag(other owner)", s.RemoveTag(ctx, other.ID, tag.ID), errorsExt.ErrNotFound)
+	if err := s.RemoveTag(ctx, user.ID, tag.ID); err != nil {
+		t.Fatalf("RemoveTag: %v", err)
+	}
+	expectErr(t, "RemoveTag(again)", s.RemoveTag(ctx, user.ID, tag.ID), errorsExt.ErrNotFound)
+
+	err = s.AddTag(ctx, &models.UserTag{ID: uuid.New(), UserID: uuid.New(), Value: "x"})
+	expectErr(t, "AddTag(missing user)", err, errorsExt.ErrNotFound)
+}
+
+func testBumpTagsVersion(t *testing.T, s storage.UserStorage) {
+	ctx := context.Background()
+	user := newUser("Ivan")
+	mustCreate(t, s, user)
+
+	for want := int64(1); want <= 2; want++ {
+		version, err := s.BumpTagsVersion(ctx, user.ID)
+		if err != nil || version != want {
+			t.Fatalf("BumpTagsVersion = %d, %v; want %d", version, err, want)
+		}
+	}
+	if got := mustGet(t, s, user.ID); got.TagsVersion != 2 {
+		t.Fatalf("TagsVersion = %d, want 2", got.TagsVersion)
+	}
+
+	_, err := s.BumpTagsVersion(ctx, uuid.New())
+	expectErr(t, "BumpTagsVersion(missing)", err, errorsExt.ErrNotFound)
+}
+
+func testListUsers(t *testing.T, s storage.UserStorage) {
+	ctx := context.Background()
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	var want []uuid.UUID
+	for i := range 5 {
+		user := newUser("User")
+		user.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		mustCreate(t, s, user)
+		want = append(want, user.ID)
+	}
+	slices.Reverse(want) // новые первыми
+
+	users, err := s.ListUsers(ctx, 1, 3)
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+	if got := userIDs(users); !slices.Equal(got, want[1:4]) {
+		t.Fatalf("ListUsers(1, 3) = %v, want %v", got, want[1:4])
+	}
+
+	users, err = s.ListUsers(ctx, 10, 3)
+	if err != nil || len(users) != 0 {
+		t.Fatalf("ListUsers past the end = %v, %v", users, err)
+	}
+}
+
+func testListUsersAfter(t *testing.T, s storage.UserStorage) {
+	ctx := context.Background()
+	var want []uuid.UUID
+	for range 5 {
+		user := newUser("User")
+		mustCreate(t, s, user)
+		want = append(want, user.ID)
+	}
+	slices.SortFunc(want, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) })
+	tag := mustAddTag(t, s, want[1], "music")
+
+	users, err := s.ListUsersAfter(ctx, uuid.Nil, 2)
+	if err != nil {
+		t.Fatalf("ListUsersAfter: %v", err)
+	}
+	if got := userIDs(users); !slices.Equal(got, want[:2]) {
+		t.Fatalf("first page = %v, want %v", got, want[:2])
+	}
+	if len(users[1].Tags) != 1 || users[1].Tags[0].ID != tag.ID {
+		t.Fatalf("tags not loaded: %+v", users[1].Tags)
+	}
+
+	users, err = s.ListUsersAfter(ctx, want[1], 10)
+	if err != nil {
+		t.Fatalf("ListUsersAfter: %v", err)
+	}
+	if got := userIDs(users); !slices.Equal(got, want[2:]) {
+		t.Fatalf("next page = %v, want %v", got, want[2:])
+	}
+}
+
+func testSearchUsers(t *testing.T, s storage.UserStorage) {
+	ctx := context.Background()
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	born := func(year int) *time.Time {
+		date := time.Date(year, time.March, 1, 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+	female, intj := models.GenderFemale, "INTJ"
+
+	// От старых к новым: a, b, c, d, e
+	a, b, c, d, e := newUser("A"), newUser("B"), newUser("C"), newUser("D"), newUser("E")
+	a.BirthDate, b.BirthDate, c.BirthDate = born(1990), born(2000), born(2005)
+	b.Gender, c.Gender = &female, &female
+	b.JungResult = &intj
+	d.Hidden = true
+	for i, user := range []*models.User{a, b, c, d, e} {
+		user.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		mustCreate(t, s, user)
+	}
+	if err := s.UpdateUser(ctx, e.ID, map[string]interface{}{"banned_at": time.Now()}); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	mustAddTag(t, s, a.ID, "music")
+	mustAddTag(t, s, a.ID, "chess")
+	mustAddTag(t, s, b.ID, "music")
+	photo := mustAddPhoto(t, s, b.ID)
+
+	search := func(filter storage.UserFilter) []uuid.UUID {
+		t.Helper()
+		users, err := s.SearchUsers(ctx, filter)
+		if err != nil {
+			t.Fatalf("SearchUsers(%+v): %v", filter, err)
+		}
+		return userIDs(users)
+	}
+	tests := []struct {
+		name   string
+		filter storage.UserFilter
+		want   []uuid.UUID
+	}{
+		{"visible", storage.UserFilter{}, []uuid.UUID{c.ID, b.ID, a.ID}},
+		{"include hidden", storage.UserFilter{IncludeHidden: true}, []uuid.UUID{e.ID, d.ID, c.ID, b.ID, a.ID}},
+		{"gender", storage.UserFilter{Gender: &female}, []uuid.UUID{c.ID, b.ID}},
+		{"born", storage.UserFilter{BornFrom: born(1995), BornTo: born(2005)}, []uuid.UUID{b.ID}},
+		{"jung", storage.UserFilter{JungResult: &intj}, []uuid.UUID{b.ID}},
+		{"any tag", storage.UserFilter{Tags: []string{"music", "chess"}}, []uuid.UUID{b.ID, a.ID}},
+		{"all tags", storage.UserFilter{Tags: []string{"music", "chess"}, AllTags: true}, []uuid.UUID{a.ID}},
+		{"created", storage.UserFilter{CreatedFrom: &b.CreatedAt, CreatedTo: &c.CreatedAt}, []uuid.UUID{b.ID}},
+		{"limit", storage.UserFilter{Limit: 2}, []uuid.UUID{c.ID, b.ID}},
+		{"after", storage.UserFilter{After: &storage.UserCursor{CreatedAt: b.CreatedAt, ID: b.ID}}, []uuid.UUID{a.ID}},
+	}
+	for _, tt := range tests {
+		if got := search(tt.filter); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: SearchUsers = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	users, err := s.SearchUsers(ctx, storage.UserFilter{JungResult: &intj})
+	if err != nil || len(users) != 1 {
+		t.Fatalf("SearchUsers = %v, %v", users, err)
+	}
+	if len(users[0].Photos) != 1 || users[0].Photos[0].ID != photo.ID || len(users[0].Tags) != 1 {
+		t.Fatalf("photos and tags not loaded: %+v", users[0])
+	}
+
+	// Курсор с тем же created_at сравнивается по id
+	twin := newUser("Twin")
+	twin.CreatedAt = a.CreatedAt
+	mustCreate(t, s, twin)
+	first, second := a.ID, twin.ID
+	if bytes.Compare(first[:], second[:]) < 0 {
+		first, second = second, first
+	}
+	got := search(storage.UserFilter{After: &storage.UserCursor{CreatedAt: a.CreatedAt, ID: first}})
+	if !slices.Equal(got, []uuid.UUID{second}) {
+		t.Fatalf("after tie = %v, want %v", got, second)
+	}
+}
+
+func testSearchUsersFullText(t *testing.T, s storage.UserStorage) {
+	ctx := context.Background()
+	named, tagged, about, other := newUser("Garfield"), newUser("Anna"), newUser("Olga"), newUser("Petr")
+	aboutText := "<script>garfield</script> люблю музыку"
+	about.AboutMyself = &aboutText
+	for _, user := range []*models.User{named, tagged, about, other} {
+		mustCreate(t, s, user)
+	}
+	mustAddTag(t, s, tagged.ID, "garfield")
+	mustAddTag(t, s, other.ID, "chess")
+
+	search := func(query string, offset, limit int) []*models.UserSearchHit {
+		t.Helper()
+		hits, err := s.SearchUsersFullText(ctx, query, offset, limit)
+		if err != nil {
+			t.Fatalf("SearchUsersFullText(%q): %v", query, err)
+		}
+		return hits
+	}
+	hitIDs := func(hits []*models.UserSearchHit) []uuid.UUID {
+		var ids []uuid.UUID
+		for _, hit := range hits {
+			ids = append(ids, hit.User.ID)
+		}
+		return ids
+	}
+
+	// Имя весит больше тегов, теги - больше "о себе"
+	hits := search("garfield", 0, 10)
+	if got, want := hitIDs(hits), []uuid.UUID{named.ID, tagged.ID, about.ID}; !slices.Equal(got, want) {
+		t.Fatalf("search garfield = %v, want %v", got, want)
+	}
+	if len(hits[1].User.Tags) != 1 {
+		t.Fatalf("tags not loaded: %+v", hits[1].User)
+	}
+	snippet := hits[2].Snippet
+	if !strings.Contains(snippet, "<b>garfield</b>") || strings.Contains(snippet, "<script>") {
+		t.Fatalf("snippet = %q, want escaped text with highlighted match", snippet)
+	}
+
+	if got := hitIDs(search("garfield", 1, 1)); !slices.Equal(got, []uuid.UUID{tagged.ID}) {
+		t.Fatalf("second page = %v, want %v", got, tagged.ID)
+	}
+	if got := hitIDs(search("Музыку", 0, 10)); !slices.Equal(got, []uuid.UUID{about.ID}) {
+		t.Fatalf("search in russian = %v, want %v", got, about.ID)
+	}
+	// Нужны все слова запроса
+	if got := hitIDs(search("garfield chess", 0, 10)); len(got) != 0 {
+		t.Fatalf("search with words from different users = %v, want none", got)
+	}
+	if got := hitIDs(search("nothing", 0, 10)); len(got) != 0 {
+		t.Fatalf("search nothing = %v, want none", got)
+	}
+}
+
+func testAuditRecords(t *testing.T, s storage.UserStorage) {
+	ctx := context.Background()
+	actor, target, other := uuid.New(), uuid.New(), uuid.New()
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	var want []uuid.UUID
+	for i, targetID := range []uuid.UUID{target, other, target} {
+		record := &models.AuditRecord{
+			ID:           uuid.New(),
+			ActorID:      actor,
+			Action:       "ban_user",
+			TargetUserID: targetID,
+			CreatedAt:    base.Add(time.Duration(i) * time.Minute),
+		}
+		if err := s.AddAuditRecord(ctx, record); err != nil {
+			t.Fatalf("AddAuditRecord: %v", err)
+		}
+		if targetID == target {
+			want = append([]uuid.UUID{record.ID}, want...)
+		}
+	}
+
+	records, err := s.ListAuditRecords(ctx, &target, 0, 10)
+	if err != nil {
+		t.Fatalf("ListAuditRecords: %v", err)
+	}
+	var got []uuid.UUID
+	for _, record := range records {
+		got = append(got, record.ID)
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("ListAuditRecords(target) = %v, want %v", got, want)
+	}
+
+	records, err = s.ListAuditRecords(ctx, nil, 0, 10)
+	if err != nil || len(records) != 3 {
+		t.Fatalf("ListAuditRecords(all) = %d records, %v; want 3", len(records), err)
+	}
+}
+
+func testTxCommit(t *testing.T, s storage.UserStorage) {
+	ctx := context.Background()
+	user := newUser("Ivan")
+
+	err := s.WithTx(ctx, func(tx storage.UserStorage) error {
+		if err := tx.CreateUser(ctx, user); err != nil {
+			return err
+		}
+		// Внутри транзакции видны ее собственные изменения
+		if got, err := tx.GetUserByID(ctx, user.ID); err != nil || got == nil {
+			t.Errorf("user not visible inside tx: %v, %v", got, err)
+		}
+		_, err := tx.BumpTagsVersion(ctx, user.ID)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+	if got := mustGet(t, s, user.ID); got.TagsVersion != 1 {
+		t.Fatalf("TagsVersion after commit = %d, want 1", got.TagsVersion)
+	}
+}
+
+func testTxRollback(t *testing.T, s storage.UserStorage) {
+	ctx := context.Background()
+	user := newUser("Ivan")
+	mustCreate(t, s, user)
+	photo := mustAddPhoto(t, s, user.ID)
+
+	failure := errors.New("failure")
+	err := s.WithTx(ctx, func(tx storage.UserStorage) error {
+		if err := tx.UpdateUserName(ctx, user.ID, "Changed"); err != nil {
+			return err
+		}
+		if err := tx.RemovePhoto(ctx, user.ID, photo.ID); err != nil {
+			return err
+		}
+		if err := tx.CreateUser(ctx, newUser("New")); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("WithTx = %v, want %v", err, failure)
+	}
+
+	if got := mustGet(t, s, user.ID); got.Name != "Ivan" {
+		t.Fatalf("name after rollback = %s, want Ivan", got.Name)
+	}
+	if photos, _ := s.GetUserPhotos(ctx, user.ID); len(photos) != 1 {
+		t.Fatalf("photos after rollback = %d, want 1", len(photos))
+	}
+	if users, _ := s.ListUsers(ctx, 0, 10); len(users) != 1 {
+		t.Fatalf("users after rollback = %d, want 1", len(users))
+	}
+}
+
+func testTxCanceledContext(t *testing.T, s storage.UserStorage) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	user := newUser("Ivan")
+
+	err := s.WithTx(ctx, func(tx storage.UserStorage) error {
+		if err := tx.CreateUser(ctx, user); err != nil {
+			return err
+		}
+		cancel()
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("WithTx with canceled context committed")
+	}
+	if got, _ := s.GetUserByID(context.Background(), user.ID); got != nil {
+		t.Fatalf("user created by canceled tx")
+	}
+}
+
+func newUser(name string) *models.User {
+	return newUserWithID(uuid.New(), name)
+}
+
+func newUserWithID(id uuid.UUID, name string) *models.User {
+	gender := models.GenderMale
+	return &models.User{ID: id, Name: name, Surname: "Ivanov", Gender: &gender}
+}
+
+func mustCreate(t *testing.T, s storage.UserStorage, user *models.User) {
+	t.Helper()
+	if err := s.CreateUser(context.Background(), user); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+}
+
+func mustGet(t *testing.T, s storage.UserStorage, id uuid.UUID) *models.User {
+	t.Helper()
+	user, err := s.GetUserByID(context.Background(), id)
+	if err != nil || user == nil {
+		t.Fatalf("GetUserByID(%s) = %v, %v", id, user, err)
+	}
+	return user
+}
+
+func mustAddPhoto(t *testing.T, s storage.UserStorage, userID uuid.UUID) *models.UserPhoto {
+	t.Helper()
+	id := uuid.New()
+	photo := &models.UserPhoto{ID: id, UserID: userID, URL: "photos/" + id.String() + ".jpg"}
+	if err := s.AddPhoto(context.Background(), photo); err != nil {
+		t.Fatalf("AddPhoto: %v", err)
+	}
+	return photo
+}
+
+func mustAddTag(t *testing.T, s storage.UserStorage, userID uuid.UUID, value string) *models.UserTag {
+	t.Helper()
+	tag := &models.UserTag{ID: uuid.New(), UserID: userID, Value: value}
+	if err := s.AddTag(context.Background(), tag); err != nil {
+		t.Fatalf("AddTag: %v", err)
+	}
+	return tag
+}
+
+func expectErr(t *testing.T, op string, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Fatalf("%s = %v, want %v", op, err, want)
+	}
+}
+
+func photoIDs(photos []*models.UserPhoto) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, photo := range photos {
+		ids = append(ids, photo.ID)
+	}
+	return ids
+}
+
+func userIDs(users []*models.User) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
+
+// sameIDs сравнивает без учета порядка: порядок строк без ORDER BY не гарантирован.
+func sameIDs(got, want []uuid.UUID) bool {
+	less := func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) }
+	got, want = slices.Clone(got), slices.Clone(want)
+	slices.SortFunc(got, less)
+	slices.SortFunc(want, less)
+	return slices.Equal(got, want)
+}
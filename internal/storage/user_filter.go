@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kerilOvs/profile_sevice/internal/models"
+)
+
+// UserFilter - условия SearchUsers. Пустое поле не фильтрует, интервалы полуоткрытые [From, To).
+type UserFilter struct {
+	Gender     *models.UserGender
+	BornFrom   *time.Time
+	BornTo     *time.Time
+	JungResult *string
+	// Tags - значения тегов: пользователь подходит, если у него есть любой из них,
+	// или все сразу при AllTags
+	Tags        []string
+	AllTags     bool
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// IncludeHidden - отдавать скрытых и забаненных
+	IncludeHidden bool
+
+	// After - последний пользователь предыдущей страницы
+	After *UserCursor
+	// Limit <= 0 - без ограничения
+	Limit int
+}
+
+// UserCursor - позиция в выдаче SearchUsers, упорядоченной по (created_at, id) по убыванию.
+type UserCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}